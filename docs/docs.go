@@ -19,25 +19,72 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/instances": {
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Provides a list of instances",
+                "description": "Lists recorded mutating calls, newest first. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get instances",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp, inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp, exclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by instance",
+                        "name": "instanceId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by action, e.g. instance.update",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Instance"
+                                "$ref": "#/definitions/model.AuditEvent"
                             }
                         }
                     },
@@ -50,45 +97,36 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/compensations": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Updates an instance",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists rollback and cleanup steps of failed operations which could not be completed yet. They are retried by the reconciliation. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update an instance",
-                "parameters": [
-                    {
-                        "description": "Instance to update",
-                        "name": "instance",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Instance"
-                        }
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Get pending compensations",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Compensation"
+                            }
+                        }
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -96,94 +134,67 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
+            }
+        },
+        "/admin/compensations/retry": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Creates an instance",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Retries all pending compensations immediately. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create an instance",
-                "parameters": [
-                    {
-                        "description": "Instance to create",
-                        "name": "instance",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Instance"
-                        }
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Retry pending compensations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Instance"
+                            "$ref": "#/definitions/model.CompensationReport"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/drift": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Deletes a single instance",
+                "description": "Compares the configuration and image of every deployed instance with the currently rendered configuration. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete instances",
-                "parameters": [
-                    {
-                        "description": "IDs of the instances to delete",
-                        "name": "id",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Get configuration drift",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.DriftReport"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -191,86 +202,67 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/instances/{id}": {
-            "get": {
+        "/admin/drift/redeploy": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Provides a single instance",
+                "description": "Detects configuration drift and redeploys every drifted instance with its current configuration. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get instance",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID of the requested instance",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Redeploy drifted instances",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Instance"
+                            "$ref": "#/definitions/model.DriftReport"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/orphans": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Deletes a single instance",
+                "description": "Lists workloads of the deployment backend which do not belong to any instance (dry run of the cleanup). Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete instance",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID of the instance to delete",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Get orphaned workloads",
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.OrphanReport"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -278,159 +270,250 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/permissions/accessible/kafka2mqtt": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "list accessible resource ids",
+                "description": "Removes workloads of the deployment backend which do not belong to any instance. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
-                ],
-                "summary": "list accessible resource ids",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
-                        "name": "permissions",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "limits size of result; 0 means unlimited",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "offset to be used in combination with limit",
-                        "name": "offset",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "Remove orphaned workloads",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/model.OrphanReport"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/permissions/check/kafka2mqtt": {
+        "/admin/quotas": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "check multiple permissions",
+                "description": "Lists the instance quotas of users and roles which override default_instance_quota. Requires admin role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
-                ],
-                "summary": "check multiple permissions",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Resource Ids, comma seperated",
-                        "name": "ids",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
-                        "name": "permissions",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "List quota overrides",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "boolean"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Quota"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/permissions/check/kafka2mqtt/{id}": {
-            "get": {
+        "/admin/quotas/{type}/{subject}": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "check permission",
-                "produces": [
+                "description": "Sets the instance quota of a user or role. A user quota wins over role quotas, of which the most generous applies. A limit of 0 means unlimited. Requires admin role.",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "admin"
                 ],
-                "summary": "check permission",
+                "summary": "Set quota override",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Resource Id",
-                        "name": "id",
+                        "description": "user or role",
+                        "name": "type",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
-                        "name": "permissions",
-                        "in": "query"
+                        "description": "user id or role name",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "only Limit is used",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes the instance quota of a user or role. Requires admin role.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove quota override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user or role",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id or role name",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/upgrade": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
                     }
                 ],
+                "description": "Provides the progress of the current or last rolling upgrade. Requires admin role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get rolling upgrade progress",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "boolean"
+                            "$ref": "#/definitions/model.UpgradeProgress"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Redeploys all instances with the configured transfer image in batches. Requires admin role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Start rolling upgrade",
+                "parameters": [
+                    {
+                        "description": "batch size, pause between batches and failure limit",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpgradeOptions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.UpgradeProgress"
                         }
                     },
                     "400": {
@@ -439,37 +522,69 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Stops the running rolling upgrade after the current batch. Requires admin role.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Cancel rolling upgrade",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
             }
         },
-        "/permissions/manage/kafka2mqtt": {
+        "/deleted-instances": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "lists resources the user has admin rights to",
+                "description": "Lists the instances in the trash the caller may read, most recently deleted first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "trash"
                 ],
-                "summary": "lists resources the user has admin rights to",
+                "summary": "List deleted instances",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "limits size of result; 0 means unlimited",
+                        "description": "default 100",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "offset to be used in combination with limit",
+                        "description": "default 0",
                         "name": "offset",
                         "in": "query"
                     }
@@ -480,7 +595,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Resource"
+                                "$ref": "#/definitions/model.Instance"
                             }
                         }
                     },
@@ -490,116 +605,85 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "403": {
-                        "description": "Forbidden"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/permissions/manage/kafka2mqtt/{id}": {
-            "get": {
+        "/deleted-instances/{id}": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "get resource, requesting user must have admin right  on the resource",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Permanently removes an instance from the trash before its retention period ends",
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "trash"
                 ],
-                "summary": "get resource",
+                "summary": "Purge deleted instance",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Resource Id",
+                        "description": "ID of the instance",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.Resource"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "403": {
-                        "description": "Forbidden"
+                    "404": {
+                        "description": "Not Found"
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/deleted-instances/{id}/restore": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "get resource rights, requesting user must have admin right",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Redeploys an instance from the trash. The consumer group is kept, so the instance continues where it stopped.",
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "trash"
                 ],
-                "summary": "set resource rights",
+                "summary": "Restore deleted instance",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Resource Id",
+                        "description": "ID of the instance",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "if set to true, the response will be sent after the corresponding kafka done signal has been received",
-                        "name": "wait",
-                        "in": "query"
-                    },
-                    {
-                        "description": "Topic",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.ResourcePermissions"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.ResourcePermissions"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
-                    "403": {
-                        "description": "Forbidden"
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "retention period ended"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -607,135 +691,3153 @@ const docTemplate = `{
                 }
             }
         },
-        "/permissions/permissions/kafka2mqtt": {
+        "/instance-definitions": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "description": "Exports all instances the caller may read as portable definitions without ids and secrets. Use format=yaml or an Accept header of application/yaml for YAML.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/yaml"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "definitions"
                 ],
-                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids",
+                "summary": "Export instance definitions",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Resource Ids, comma seperated",
-                        "name": "ids",
-                        "in": "query",
-                        "required": true
+                        "description": "json (default) or yaml",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ComputedPermissions"
-                            }
+                            "$ref": "#/definitions/model.InstanceDefinitions"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/permissions/query/permissions/kafka2mqtt": {
+            },
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "description": "Creates or updates instances from a JSON or YAML document. Definitions are matched by name with the instances the caller may write,\nmissing passwords of unchanged custom brokers are kept. With atomic=true nothing is changed if any definition fails.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions-kafka2mqtt"
+                    "definitions"
                 ],
-                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids",
+                "summary": "Import instance definitions",
                 "parameters": [
                     {
-                        "description": "Resource Ids",
-                        "name": "ids",
+                        "description": "definitions to import",
+                        "name": "definitions",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/model.InstanceDefinitions"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all or nothing",
+                        "name": "atomic",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ComputedPermissions"
-                            }
+                            "$ref": "#/definitions/model.ImportReport"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "atomic import aborted",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "429": {
+                        "description": "atomic import exceeds the instance quota",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Provides a list of instances",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get instances",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only instances expiring within the duration, e.g. 72h",
+                        "name": "expiresWithin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name (default), id, created_at, updated_at or expires_at, append :desc to sort descending",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Instance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Updates an instance. The workload is only redeployed if its configuration changes. With dryRun=true the update is only validated and the rendered configuration is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update an instance",
+                "parameters": [
+                    {
+                        "description": "Instance to update",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate only",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the instance, the update is rejected if it has been modified since. Weak ETags never match.",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "queue the update and respond with the operation",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "only with dryRun=true",
+                        "schema": {
+                            "$ref": "#/definitions/model.ValidationResult"
+                        }
+                    },
+                    "202": {
+                        "description": "only with async=true",
+                        "schema": {
+                            "$ref": "#/definitions/model.Operation"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "operation status"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "description": "modified since the ETag of If-Match"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates an instance. With dryRun=true the instance is only validated and the rendered configuration is returned as model.ValidationResult.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create an instance",
+                "parameters": [
+                    {
+                        "description": "Instance to create",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate only",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "queue the creation and respond with the operation",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    "202": {
+                        "description": "only with async=true",
+                        "schema": {
+                            "$ref": "#/definitions/model.Operation"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "operation status"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Moves all given instances or none of them to the trash. Their workloads are removed, they can be restored until the retention period ends.\nIf-Match is rejected, conditional deletes are only supported by DELETE /instances/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete instances",
+                "parameters": [
+                    {
+                        "description": "IDs of the instances to delete",
+                        "name": "id",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.ComputedPermissions": {
+        },
+        "/instances/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Provides a single instance",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the requested instance",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "revision of the instance, for If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Moves a single instance to the trash. Its workload is removed, it can be restored with /deleted-instances/{id}/restore until the retention period ends.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to delete",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the instance, the delete is rejected if it has been modified since. Weak ETags never match.",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "description": "modified since the ETag of If-Match"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Applies a JSON Merge Patch (RFC 7396) to an instance: omitted fields, including the custom mqtt password, are kept and null removes a field.\nThe workload is only redeployed if its configuration changes, e.g. renaming an instance does not restart it.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Patch an instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to patch",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETags of the instance, the patch is rejected if it has been modified since. Weak ETags never match.",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "queue the patch and respond with the operation",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "revision of the patched instance"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "only with async=true",
+                        "schema": {
+                            "$ref": "#/definitions/model.Operation"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "operation status"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "description": "modified since the ETag of If-Match"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates a new instance from an existing one. Fields set in the body replace the copied values. The custom mqtt password is only copied with write permission on the source.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Clone instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to clone",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "fields to change, e.g. Name and Filter",
+                        "name": "override",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Provides the logs of the transfer container of an instance. With follow=true the response is streamed until the client disconnects.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Get instance logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of lines from the end of the log, 0 for all lines, defaults to 100",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only lines since this RFC3339 timestamp or duration (e.g. 15m)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "stream new log lines",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "prefix lines with timestamps",
+                        "name": "timestamps",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "501": {
+                        "description": "Not Implemented"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Stops the workload of an instance without deleting it",
+                "summary": "Pause instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to pause",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Restarts the consumption of an instance from a point in time or from explicit partition offsets. The workload is redeployed with a new consumer group,\npartitions without requested offset continue at their current position. Paused and unscheduled instances start at these offsets once they run again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replay instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to replay",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "either From or Offsets",
+                        "name": "replay",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReplayResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Starts the workload of a paused instance",
+                "summary": "Resume instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance to resume",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the stored revisions of an instance, newest first. Secrets are masked.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List instance revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.InstanceRevision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/revisions/{revision}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the configuration fields changed between two revisions. Secrets are masked.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Diff instance revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to compare from",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to compare to, defaults to the current version",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.InstanceDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/instances/{id}/revisions/{revision}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Updates and redeploys the instance with the configuration of the given revision. The result is stored as a new revision.",
+                "summary": "Roll back instance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the instance",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision to roll back to",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/operations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Reports status, result and error of an operation queued with async=true. Only the submitting user and admins can read an operation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the operation",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Operation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/accessible/kafka2mqtt": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list accessible resource ids",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "list accessible resource ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/accessible/kafka2mqtt_templates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list accessible resource ids",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "list accessible resource ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/check/kafka2mqtt": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check multiple permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "check multiple permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/check/kafka2mqtt/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "check permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/check/kafka2mqtt_templates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check multiple permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "check multiple permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/check/kafka2mqtt_templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "check permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/manage/kafka2mqtt": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "lists resources the user has admin rights to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "lists resources the user has admin rights to",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/manage/kafka2mqtt/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource, requesting user must have admin right  on the resource",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "get resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Resource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource rights, requesting user must have admin right",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "set resource rights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if set to true, the response will be sent after the corresponding kafka done signal has been received",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Topic",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/manage/kafka2mqtt_templates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "lists resources the user has admin rights to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "lists resources the user has admin rights to",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/manage/kafka2mqtt_templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource, requesting user must have admin right  on the resource",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "get resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Resource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource rights, requesting user must have admin right",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "set resource rights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if set to true, the response will be sent after the corresponding kafka done signal has been received",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Topic",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/permissions/kafka2mqtt": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/permissions/kafka2mqtt_templates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic (kafka2mqtt_templates) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt_templates) and ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/query/permissions/kafka2mqtt": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt"
+                ],
+                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt) and ids",
+                "parameters": [
+                    {
+                        "description": "Resource Ids",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/query/permissions/kafka2mqtt_templates": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic (kafka2mqtt_templates) and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions-kafka2mqtt_templates"
+                ],
+                "summary": "list the computed permissions to resources of the given topic (kafka2mqtt_templates) and ids",
+                "parameters": [
+                    {
+                        "description": "Resource Ids",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/quota": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Compares the instances of the requesting user with their instance quota. A limit of 0 means unlimited.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quota"
+                ],
+                "summary": "Get quota usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.QuotaUsage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists all templates the caller may read, CustomMqttPassword is masked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.templateList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates a template with settings shared by multiple instances",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create template",
+                "parameters": [
+                    {
+                        "description": "Template to create",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Template"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Provides the template, CustomMqttPassword is masked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Get template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Template"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Updates a template. With propagate=true all linked instances the caller may write are updated and redeployed.\nA CustomMqttPassword of \"***\", as returned by reads, keeps the stored password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Update template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template to update",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Template"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "update linked instances",
+                        "name": "propagate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TemplatePropagationReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Deletes a template. Linked instances keep their settings.",
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Delete template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/templates/{id}/instances": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Creates an instance linked to the template. Offset, Values, custom broker settings and ImageVersion are taken from the template,\nthe body provides the instance specific fields like Name, FilterType, Filter and Topic.\nThe CustomMqttPassword of the template is only used if the caller may write the template, otherwise the one of the body is used.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create instance from template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "instance specific fields",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "429": {
+                        "description": "Too Many Requests"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the webhooks of the requesting user. Secrets are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Registers a webhook for deployment state changes of a single instance (InstanceId set, requires read permission) or of all own instances.\nNotifications are posted as JSON with the headers X-Event, X-Delivery, X-Timestamp and X-Signature, which contains \"sha256=\" and the hex encoded HMAC-SHA256 of \"\u003cX-Timestamp\u003e.\u003cbody\u003e\" keyed with the secret.\nA secret is generated if none is given, it is only returned in this response. Failed deliveries are retried with exponential backoff.\nUrls of loopback, private, link-local or other internal addresses are rejected, also if a host resolves to one of them on delivery.\nNotifications are only sent while the user can read the instance. A webhook of a single instance is removed once the user lost read permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Url and optionally InstanceId, States and Secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes a webhook of the requesting user and its delivery log.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the delivery log of a webhook of the requesting user, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 0",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/test": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Posts a test notification to a webhook of the requesting user once and returns the logged delivery. A failed delivery is reported in its Status, it is not retried.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Test webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the webhook",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.templateList": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Template"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "Action": {
+                    "type": "string"
+                },
+                "Changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FieldChange"
+                    }
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Outcome": {
+                    "type": "string"
+                },
+                "TemplateId": {
+                    "type": "string"
+                },
+                "Timestamp": {
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Compensation": {
+            "type": "object",
+            "properties": {
+                "Action": {
+                    "type": "string"
+                },
+                "Attempts": {
+                    "type": "integer"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "Image": {
+                    "description": "restore_instance: previously deployed image",
+                    "type": "string"
+                },
+                "Instance": {
+                    "description": "restore_instance: previous version of the instance",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    ]
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "KafkaGroupId": {
+                    "description": "restore_instance: previously deployed consumer group",
+                    "type": "string"
+                },
+                "LastError": {
+                    "type": "string"
+                },
+                "ServiceId": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CompensationReport": {
+            "type": "object",
+            "properties": {
+                "Failed": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "Resolved": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Retried": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ComputedPermissions": {
+            "type": "object",
+            "properties": {
+                "administrate": {
+                    "type": "boolean"
+                },
+                "execute": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "write": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.DeploymentStatus": {
+            "type": "object",
+            "properties": {
+                "BackendState": {
+                    "type": "string"
+                },
+                "Message": {
+                    "type": "string"
+                },
+                "Restarts": {
+                    "type": "integer"
+                },
+                "State": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DriftReport": {
+            "type": "object",
+            "properties": {
+                "Checked": {
+                    "type": "integer"
+                },
+                "Drifted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InstanceDrift"
+                    }
+                }
+            }
+        },
+        "model.EnvDrift": {
+            "type": "object",
+            "properties": {
+                "Deployed": {
+                    "type": "string"
+                },
+                "Desired": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.FieldChange": {
+            "type": "object",
+            "properties": {
+                "Field": {
+                    "type": "string"
+                },
+                "From": {},
+                "To": {}
+            }
+        },
+        "model.ImportItemResult": {
+            "type": "object",
+            "properties": {
+                "Action": {
+                    "type": "string"
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ImportReport": {
+            "type": "object",
+            "properties": {
+                "Applied": {
+                    "type": "boolean"
+                },
+                "Items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ImportItemResult"
+                    }
+                }
+            }
+        },
+        "model.Instance": {
+            "type": "object",
+            "required": [
+                "EntityName",
+                "Filter",
+                "FilterType",
+                "Name",
+                "Offset",
+                "ServiceName",
+                "Topic"
+            ],
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "CustomMqttBaseTopic": {
+                    "type": "string"
+                },
+                "CustomMqttBroker": {
+                    "type": "string"
+                },
+                "CustomMqttPassword": {
+                    "type": "string"
+                },
+                "CustomMqttUser": {
+                    "type": "string"
+                },
+                "DeletedAt": {
+                    "description": "set while the instance is in the trash",
+                    "type": "string"
+                },
+                "Description": {
+                    "type": "string"
+                },
+                "EntityName": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "ExpiryAction": {
+                    "description": "one of the ExpiryAction constants, defaults to ExpiryActionDelete",
+                    "type": "string"
+                },
+                "ExpiryWarnedAt": {
+                    "description": "set when the expiry warning has been sent",
+                    "type": "string"
+                },
+                "Filter": {
+                    "type": "string"
+                },
+                "FilterType": {
+                    "type": "string"
+                },
+                "ID": {
+                    "type": "string"
+                },
+                "ImageVersion": {
+                    "type": "string"
+                },
+                "KafkaGroupId": {
+                    "description": "active consumer group, empty for the default group named like the id",
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Offset": {
+                    "type": "string"
+                },
+                "Paused": {
+                    "type": "boolean"
+                },
+                "Revision": {
+                    "type": "integer"
+                },
+                "Schedule": {
+                    "$ref": "#/definitions/model.Schedule"
+                },
+                "ScheduleState": {
+                    "description": "one of the ScheduleState constants, maintained by the scheduler",
+                    "type": "string"
+                },
+                "ServiceName": {
+                    "type": "string"
+                },
+                "Status": {
+                    "$ref": "#/definitions/model.DeploymentStatus"
+                },
+                "TemplateId": {
+                    "type": "string"
+                },
+                "Topic": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "type": "string"
+                },
+                "Values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Value"
+                    }
+                },
+                "generated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.InstanceDefinition": {
+            "type": "object",
+            "properties": {
+                "CustomMqttBaseTopic": {
+                    "type": "string"
+                },
+                "CustomMqttBroker": {
+                    "type": "string"
+                },
+                "CustomMqttPassword": {
+                    "type": "string"
+                },
+                "CustomMqttUser": {
+                    "type": "string"
+                },
+                "Description": {
+                    "type": "string"
+                },
+                "EntityName": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "ExpiryAction": {
+                    "type": "string"
+                },
+                "Filter": {
+                    "type": "string"
+                },
+                "FilterType": {
+                    "type": "string"
+                },
+                "ImageVersion": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Offset": {
+                    "type": "string"
+                },
+                "Schedule": {
+                    "$ref": "#/definitions/model.Schedule"
+                },
+                "ServiceName": {
+                    "type": "string"
+                },
+                "Topic": {
+                    "type": "string"
+                },
+                "Values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Value"
+                    }
+                }
+            }
+        },
+        "model.InstanceDefinitions": {
+            "type": "object",
+            "properties": {
+                "Instances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InstanceDefinition"
+                    }
+                }
+            }
+        },
+        "model.InstanceDiff": {
+            "type": "object",
+            "properties": {
+                "Changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FieldChange"
+                    }
+                },
+                "From": {
+                    "type": "integer"
+                },
+                "To": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.InstanceDrift": {
+            "type": "object",
+            "properties": {
+                "DeployedImage": {
+                    "type": "string"
+                },
+                "DesiredImage": {
+                    "type": "string"
+                },
+                "Env": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EnvDrift"
+                    }
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Missing": {
+                    "type": "boolean"
+                },
+                "Redeployed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.InstanceRevision": {
+            "type": "object",
+            "properties": {
+                "Instance": {
+                    "$ref": "#/definitions/model.Instance"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Revision": {
+                    "type": "integer"
+                },
+                "Timestamp": {
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Operation": {
+            "type": "object",
+            "properties": {
+                "Attempts": {
+                    "type": "integer"
+                },
+                "Code": {
+                    "description": "http status code the synchronous call would have returned",
+                    "type": "integer"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Error": {
+                    "type": "string"
+                },
+                "FinishedAt": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Result": {
+                    "description": "created or updated instance with masked secrets",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Instance"
+                        }
+                    ]
+                },
+                "StartedAt": {
+                    "type": "string"
+                },
+                "Status": {
+                    "description": "one of the OperationStatus constants",
+                    "type": "string"
+                },
+                "Type": {
+                    "description": "one of the OperationType constants",
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.OrphanReport": {
+            "type": "object",
+            "properties": {
+                "Failed": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "Orphans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Workload"
+                    }
+                },
+                "Removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.PartitionOffset": {
+            "type": "object",
+            "properties": {
+                "Offset": {
+                    "type": "integer"
+                },
+                "Partition": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PermissionsMap": {
+            "type": "object",
+            "properties": {
+                "administrate": {
+                    "type": "boolean"
+                },
+                "execute": {
+                    "type": "boolean"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "write": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Quota": {
+            "type": "object",
+            "properties": {
+                "Limit": {
+                    "type": "integer"
+                },
+                "Subject": {
+                    "description": "user id or role name",
+                    "type": "string"
+                },
+                "Type": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.QuotaUsage": {
+            "type": "object",
+            "properties": {
+                "Limit": {
+                    "type": "integer"
+                },
+                "Role": {
+                    "description": "role of the applied override",
+                    "type": "string"
+                },
+                "Source": {
+                    "description": "default, user or role",
+                    "type": "string"
+                },
+                "Used": {
+                    "type": "integer"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReplayRequest": {
+            "type": "object",
+            "properties": {
+                "From": {
+                    "type": "string"
+                },
+                "Offsets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PartitionOffset"
+                    }
+                }
+            }
+        },
+        "model.ReplayResult": {
+            "type": "object",
+            "properties": {
+                "KafkaGroupId": {
+                    "type": "string"
+                },
+                "Offsets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PartitionOffset"
+                    }
+                }
+            }
+        },
+        "model.Resource": {
             "type": "object",
             "properties": {
-                "administrate": {
-                    "type": "boolean"
-                },
-                "execute": {
-                    "type": "boolean"
+                "group_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
                 },
                 "id": {
                     "type": "string"
                 },
-                "read": {
-                    "type": "boolean"
+                "role_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
                 },
-                "write": {
-                    "type": "boolean"
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
                 }
             }
         },
-        "model.Instance": {
+        "model.ResourcePermissions": {
+            "type": "object",
+            "properties": {
+                "group_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "role_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                }
+            }
+        },
+        "model.Schedule": {
+            "type": "object",
+            "properties": {
+                "End": {
+                    "type": "string"
+                },
+                "Start": {
+                    "type": "string"
+                },
+                "TimeZone": {
+                    "description": "IANA time zone of the windows, defaults to UTC",
+                    "type": "string"
+                },
+                "Windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ScheduleWindow"
+                    }
+                }
+            }
+        },
+        "model.ScheduleWindow": {
+            "type": "object",
+            "properties": {
+                "Days": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "From": {
+                    "type": "string"
+                },
+                "To": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Template": {
             "type": "object",
             "required": [
-                "EntityName",
-                "Filter",
-                "FilterType",
                 "Name",
-                "Offset",
-                "ServiceName",
-                "Topic"
+                "Offset"
             ],
             "properties": {
                 "CreatedAt": {
@@ -756,16 +3858,10 @@ const docTemplate = `{
                 "Description": {
                     "type": "string"
                 },
-                "EntityName": {
-                    "type": "string"
-                },
-                "Filter": {
-                    "type": "string"
-                },
-                "FilterType": {
+                "ID": {
                     "type": "string"
                 },
-                "ID": {
+                "ImageVersion": {
                     "type": "string"
                 },
                 "Name": {
@@ -774,12 +3870,6 @@ const docTemplate = `{
                 "Offset": {
                     "type": "string"
                 },
-                "ServiceName": {
-                    "type": "string"
-                },
-                "Topic": {
-                    "type": "string"
-                },
                 "UpdatedAt": {
                     "type": "string"
                 },
@@ -788,78 +3878,106 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/model.Value"
                     }
-                },
-                "generated": {
-                    "type": "boolean"
                 }
             }
         },
-        "model.PermissionsMap": {
+        "model.TemplatePropagationReport": {
             "type": "object",
             "properties": {
-                "administrate": {
-                    "type": "boolean"
+                "Failed": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
-                "execute": {
-                    "type": "boolean"
+                "Updated": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.UpgradeOptions": {
+            "type": "object",
+            "properties": {
+                "BatchPause": {
+                    "description": "duration to wait between batches, e.g. \"30s\"",
+                    "type": "string"
                 },
-                "read": {
-                    "type": "boolean"
+                "Concurrency": {
+                    "description": "instances redeployed in parallel per batch, defaults to 1",
+                    "type": "integer"
                 },
-                "write": {
-                    "type": "boolean"
+                "MaxFailures": {
+                    "description": "the upgrade stops after this many failures, defaults to 1",
+                    "type": "integer"
                 }
             }
         },
-        "model.Resource": {
+        "model.UpgradeProgress": {
             "type": "object",
             "properties": {
-                "group_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "CancelRequested": {
+                    "description": "set by CancelUpgrade, polled by the replica running the upgrade",
+                    "type": "boolean"
                 },
-                "id": {
+                "Error": {
                     "type": "string"
                 },
-                "role_permissions": {
+                "Failed": {
                     "type": "object",
                     "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
+                        "type": "string"
                     }
                 },
-                "topic_id": {
+                "FinishedAt": {
                     "type": "string"
                 },
-                "user_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "Image": {
+                    "type": "string"
+                },
+                "Options": {
+                    "$ref": "#/definitions/model.UpgradeOptions"
+                },
+                "Running": {
+                    "type": "boolean"
+                },
+                "StartedAt": {
+                    "type": "string"
+                },
+                "Total": {
+                    "type": "integer"
+                },
+                "UpdatedAt": {
+                    "description": "refreshed by the replica running the upgrade while it is alive",
+                    "type": "string"
+                },
+                "Upgraded": {
+                    "type": "integer"
                 }
             }
         },
-        "model.ResourcePermissions": {
+        "model.ValidationResult": {
             "type": "object",
             "properties": {
-                "group_permissions": {
+                "Env": {
                     "type": "object",
                     "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
+                        "type": "string"
                     }
                 },
-                "role_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
+                "Errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "user_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "Image": {
+                    "type": "string"
+                },
+                "Valid": {
+                    "type": "boolean"
                 }
             }
         },
@@ -873,6 +3991,127 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "Secret": {
+                    "description": "HMAC-SHA256 key of the X-Signature header, only returned on creation",
+                    "type": "string"
+                },
+                "States": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Url": {
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "Attempts": {
+                    "type": "integer"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "FinishedAt": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "LastError": {
+                    "description": "connection error or response status of the last attempt, response bodies are not recorded",
+                    "type": "string"
+                },
+                "NextAttemptAt": {
+                    "type": "string"
+                },
+                "Notification": {
+                    "$ref": "#/definitions/model.WebhookNotification"
+                },
+                "ResponseCode": {
+                    "description": "status code of the last attempt",
+                    "type": "integer"
+                },
+                "Status": {
+                    "description": "one of the WebhookDelivery constants",
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                },
+                "WebhookId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookNotification": {
+            "type": "object",
+            "properties": {
+                "Event": {
+                    "description": "one of the WebhookEvent constants",
+                    "type": "string"
+                },
+                "From": {
+                    "description": "previous DeploymentState",
+                    "type": "string"
+                },
+                "Id": {
+                    "description": "delivery id, repeated on retries",
+                    "type": "string"
+                },
+                "InstanceId": {
+                    "type": "string"
+                },
+                "InstanceName": {
+                    "type": "string"
+                },
+                "Message": {
+                    "type": "string"
+                },
+                "Timestamp": {
+                    "type": "string"
+                },
+                "To": {
+                    "description": "current DeploymentState",
+                    "type": "string"
+                },
+                "UserId": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Workload": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Id": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, AdminEndpoints)
+}
+
+// Query godoc
+// @Summary      Get configuration drift
+// @Description  Compares the configuration and image of every deployed instance with the currently rendered configuration. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.DriftReport
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/drift [GET]
+func GetDrift() {} // for doc generation
+
+// Query godoc
+// @Summary      Redeploy drifted instances
+// @Description  Detects configuration drift and redeploys every drifted instance with its current configuration. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.DriftReport
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/drift/redeploy [POST]
+func PostDriftRedeploy() {} // for doc generation
+
+// Query godoc
+// @Summary      Get orphaned workloads
+// @Description  Lists workloads of the deployment backend which do not belong to any instance (dry run of the cleanup). Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.OrphanReport
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/orphans [GET]
+func GetOrphans() {} // for doc generation
+
+// Query godoc
+// @Summary      Remove orphaned workloads
+// @Description  Removes workloads of the deployment backend which do not belong to any instance. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.OrphanReport
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/orphans [DELETE]
+func DeleteOrphans() {} // for doc generation
+
+// Query godoc
+// @Summary      Start rolling upgrade
+// @Description  Redeploys all instances with the configured transfer image in batches. Requires admin role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        options body model.UpgradeOptions false "batch size, pause between batches and failure limit"
+// @Success      202 {object}  model.UpgradeProgress
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      409
+// @Failure      500
+// @Router       /admin/upgrade [POST]
+func PostUpgrade() {} // for doc generation
+
+// Query godoc
+// @Summary      Get rolling upgrade progress
+// @Description  Provides the progress of the current or last rolling upgrade. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.UpgradeProgress
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Router       /admin/upgrade [GET]
+func GetUpgrade() {} // for doc generation
+
+// Query godoc
+// @Summary      Cancel rolling upgrade
+// @Description  Stops the running rolling upgrade after the current batch. Requires admin role.
+// @Tags         admin
+// @Security Bearer
+// @Success      204
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Router       /admin/upgrade [DELETE]
+func DeleteUpgrade() {} // for doc generation
+
+// Query godoc
+// @Summary      Get pending compensations
+// @Description  Lists rollback and cleanup steps of failed operations which could not be completed yet. They are retried by the reconciliation. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {array}  model.Compensation
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/compensations [GET]
+func GetCompensations() {} // for doc generation
+
+// Query godoc
+// @Summary      Retry pending compensations
+// @Description  Retries all pending compensations immediately. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.CompensationReport
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/compensations/retry [POST]
+func PostCompensationsRetry() {} // for doc generation
+
+func AdminEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/admin"
+
+	router.GET(resource+"/drift", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		report, err, errCode := control.DetectDrift(request.Header.Get(authHeader), getUserId(request), false)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+
+	router.POST(resource+"/drift/redeploy", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		report, err, errCode := control.DetectDrift(request.Header.Get(authHeader), getUserId(request), true)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+
+	router.GET(resource+"/orphans", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		report, err, errCode := control.CleanupOrphans(request.Header.Get(authHeader), true)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+
+	router.POST(resource+"/upgrade", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		options := model.UpgradeOptions{}
+		if request.ContentLength != 0 {
+			err := json.NewDecoder(request.Body).Decode(&options)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		progress, err, errCode := control.StartUpgrade(request.Header.Get(authHeader), getUserId(request), options)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		writer.WriteHeader(errCode)
+		err = json.NewEncoder(writer).Encode(progress)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+
+	router.GET(resource+"/upgrade", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		progress, err, errCode := control.GetUpgradeProgress(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, progress)
+	})
+
+	router.DELETE(resource+"/upgrade", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.CancelUpgrade(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+
+	router.DELETE(resource+"/orphans", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		report, err, errCode := control.CleanupOrphans(request.Header.Get(authHeader), false)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+
+	router.GET(resource+"/compensations", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		compensations, err, errCode := control.ListCompensations(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, compensations)
+	})
+
+	router.POST(resource+"/compensations/retry", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		report, err, errCode := control.RetryCompensations(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+}
+
+func writeJson(writer http.ResponseWriter, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err := json.NewEncoder(writer).Encode(value)
+	if err != nil {
+		log.Println("ERROR: unable to encode response", err)
+	}
+}
@@ -53,6 +53,7 @@ func Start(config config.Config, ctx context.Context, control Controller, permv2
 	log.Println("start api on " + config.ApiPort)
 	router := Router(config, control)
 	router = client.EmbedPermissionsClientIntoRouter(permv2, router, "/permissions/", ForwardPermissions)
+	router = auditPermissionChanges(control, router)
 	handler := util.NewLogger(util.NewCors(router))
 	server := &http.Server{Addr: ":" + config.ApiPort, Handler: handler, WriteTimeout: 10 * time.Second, ReadTimeout: 2 * time.Second, ReadHeaderTimeout: 2 * time.Second}
 	go func() {
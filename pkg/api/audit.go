@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/api/util"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, AuditEndpoints)
+}
+
+// Query godoc
+// @Summary      List audit events
+// @Description  Lists recorded mutating calls, newest first. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Param        from query string false "RFC3339 timestamp, inclusive"
+// @Param        to query string false "RFC3339 timestamp, exclusive"
+// @Param        userId query string false "filter by user"
+// @Param        instanceId query string false "filter by instance"
+// @Param        action query string false "filter by action, e.g. instance.update"
+// @Param        limit query int false "default 100"
+// @Param        offset query int false "default 0"
+// @Success      200 {array}  model.AuditEvent
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/audit [GET]
+func GetAuditEvents() {} // for doc generation
+
+func AuditEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	router.GET("/admin/audit", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		query := model.AuditQuery{
+			UserId:     request.URL.Query().Get("userId"),
+			InstanceId: request.URL.Query().Get("instanceId"),
+			Action:     request.URL.Query().Get("action"),
+		}
+		var err error
+		query.From, err = parseTimeParam(request, "from")
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.To, err = parseTimeParam(request, "to")
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit := request.URL.Query().Get("limit")
+		if limit == "" {
+			limit = "100"
+		}
+		query.Limit, err = strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset := request.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		query.Offset, err = strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		events, err, errCode := control.ListAuditEvents(request.Header.Get(authHeader), query)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, events)
+	})
+}
+
+func parseTimeParam(request *http.Request, name string) (*time.Time, error) {
+	value := request.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// auditPermissionChanges records permission updates handled by the embedded permissions router, which bypasses the controller.
+func auditPermissionChanges(control Controller, handler http.Handler) http.Handler {
+	const prefix = "/permissions/manage/"
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPut || !strings.HasPrefix(request.URL.Path, prefix) {
+			handler.ServeHTTP(writer, request)
+			return
+		}
+		topic, id, found := strings.Cut(strings.TrimPrefix(request.URL.Path, prefix), "/")
+		if !found {
+			handler.ServeHTTP(writer, request)
+			return
+		}
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		response := &util.ResponseWriterWithStatusCodeLog{Parent: writer, Status: http.StatusOK}
+		handler.ServeHTTP(response, request)
+		control.RecordPermissionChange(getUserId(request), topic, id, body, response.Status)
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+	"sigs.k8s.io/yaml"
+)
+
+func init() {
+	endpoints = append(endpoints, DefinitionEndpoints)
+}
+
+// Query godoc
+// @Summary      Export instance definitions
+// @Description  Exports all instances the caller may read as portable definitions without ids and secrets. Use format=yaml or an Accept header of application/yaml for YAML.
+// @Tags         definitions
+// @Produce      json
+// @Produce      application/yaml
+// @Security Bearer
+// @Param        format query string false "json (default) or yaml"
+// @Success      200 {object}  model.InstanceDefinitions
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /instance-definitions [GET]
+func GetInstanceDefinitions() {} // for doc generation
+
+// Query godoc
+// @Summary      Import instance definitions
+// @Description  Creates or updates instances from a JSON or YAML document. Definitions are matched by name with the instances the caller may write,
+// @Description  missing passwords of unchanged custom brokers are kept. With atomic=true nothing is changed if any definition fails.
+// @Tags         definitions
+// @Accept       json
+// @Accept       application/yaml
+// @Produce      json
+// @Security Bearer
+// @Param        definitions body model.InstanceDefinitions true "definitions to import"
+// @Param        atomic query bool false "all or nothing"
+// @Success      200 {object}  model.ImportReport
+// @Failure      400 {object}  model.ImportReport "atomic import aborted"
+// @Failure      401
+// @Failure      403
+// @Failure      429 {object}  model.ImportReport "atomic import exceeds the instance quota"
+// @Failure      500
+// @Router       /instance-definitions [POST]
+func PostInstanceDefinitions() {} // for doc generation
+
+func DefinitionEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/instance-definitions"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		definitions, err, errCode := control.ExportInstances(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		if request.URL.Query().Get("format") != "yaml" && !isYaml(request.Header.Get("Accept")) {
+			writeJson(writer, definitions)
+			return
+		}
+		b, err := yaml.Marshal(definitions)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+		_, err = writer.Write(b)
+		if err != nil {
+			log.Println("ERROR: unable to write response", err)
+		}
+	})
+
+	router.POST(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		b, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		definitions := model.InstanceDefinitions{}
+		// yaml is a superset of json, unknown fields are rejected to point out ids or typos in hand-written documents
+		err = yaml.UnmarshalStrict(b, &definitions)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		atomic := strings.ToLower(request.URL.Query().Get("atomic")) == "true"
+		report, err, errCode := control.ImportInstances(request.Header.Get(authHeader), getUserId(request), definitions, atomic)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		writer.WriteHeader(errCode)
+		err = json.NewEncoder(writer).Encode(report)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+}
+
+func isYaml(contentType string) bool {
+	return strings.Contains(contentType, "yaml")
+}
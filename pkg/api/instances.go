@@ -18,11 +18,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
@@ -43,16 +45,21 @@ const authHeader = "Authorization"
 
 // Query godoc
 // @Summary      Create an instance
-// @Description  Creates an instance
+// @Description  Creates an instance. With dryRun=true the instance is only validated and the rendered configuration is returned as model.ValidationResult.
 // @Accept       json
 // @Produce      json
 // @Security Bearer
 // @Param        instance body model.Instance true "Instance to create"
+// @Param        dryRun query bool false "validate only"
+// @Param        async query bool false "queue the creation and respond with the operation"
 // @Success      200 {object}  model.Instance
+// @Success      202 {object}  model.Operation "only with async=true"
+// @Header       202 {string}  Location "operation status"
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      429
 // @Failure      500
 // @Router       /instances [POST]
 func PostInstances() {} // for doc generation
@@ -62,6 +69,8 @@ func PostInstances() {} // for doc generation
 // @Description  Provides a list of instances
 // @Produce      json
 // @Security Bearer
+// @Param        expiresWithin query string false "only instances expiring within the duration, e.g. 72h"
+// @Param        order query string false "name (default), id, created_at, updated_at or expires_at, append :desc to sort descending"
 // @Success      200 {array}  model.Instance
 // @Failure      400
 // @Failure      401
@@ -78,6 +87,7 @@ func GetInstances() {} // for doc generation
 // @Security Bearer
 // @Param        id path string true "ID of the requested instance"
 // @Success      200 {object}  model.Instance
+// @Header       200 {string}  ETag "revision of the instance, for If-Match"
 // @Failure      400
 // @Failure      401
 // @Failure      403
@@ -88,38 +98,72 @@ func GetInstance() {} // for doc generation
 
 // Query godoc
 // @Summary      Update an instance
-// @Description  Updates an instance
+// @Description  Updates an instance. The workload is only redeployed if its configuration changes. With dryRun=true the update is only validated and the rendered configuration is returned.
 // @Accept       json
 // @Produce      json
 // @Security Bearer
 // @Param        instance body model.Instance true "Instance to update"
-// @Success      200
+// @Param        dryRun query bool false "validate only"
+// @Param        If-Match header string false "ETags of the instance, the update is rejected if it has been modified since. Weak ETags never match."
+// @Param        async query bool false "queue the update and respond with the operation"
+// @Success      200 {object}  model.ValidationResult "only with dryRun=true"
+// @Success      202 {object}  model.Operation "only with async=true"
+// @Header       202 {string}  Location "operation status"
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      412 "modified since the ETag of If-Match"
 // @Failure      500
 // @Router       /instances [PUT]
 func PutInstances() {} // for doc generation
 
+// Query godoc
+// @Summary      Patch an instance
+// @Description  Applies a JSON Merge Patch (RFC 7396) to an instance: omitted fields, including the custom mqtt password, are kept and null removes a field.
+// @Description  The workload is only redeployed if its configuration changes, e.g. renaming an instance does not restart it.
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the instance to patch"
+// @Param        patch body model.Instance true "fields to change"
+// @Param        If-Match header string false "ETags of the instance, the patch is rejected if it has been modified since. Weak ETags never match."
+// @Param        async query bool false "queue the patch and respond with the operation"
+// @Success      200 {object}  model.Instance
+// @Header       200 {string}  ETag "revision of the patched instance"
+// @Success      202 {object}  model.Operation "only with async=true"
+// @Header       202 {string}  Location "operation status"
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      412 "modified since the ETag of If-Match"
+// @Failure      500
+// @Router       /instances/{id} [PATCH]
+func PatchInstance() {} // for doc generation
+
 // Query godoc
 // @Summary      Delete instance
-// @Description  Deletes a single instance
+// @Description  Moves a single instance to the trash. Its workload is removed, it can be restored with /deleted-instances/{id}/restore until the retention period ends.
 // @Produce      json
 // @Security Bearer
 // @Param        id path string true "ID of the instance to delete"
+// @Param        If-Match header string false "ETags of the instance, the delete is rejected if it has been modified since. Weak ETags never match."
 // @Success      200
 // @Failure      400
 // @Failure      401
 // @Failure      403
 // @Failure      404
+// @Failure      412 "modified since the ETag of If-Match"
 // @Failure      500
 // @Router       /instances/{id} [DELETE]
 func DeleteInstance() {} // for doc generation
 
 // Query godoc
 // @Summary      Delete instances
-// @Description  Deletes a single instance
+// @Description  Moves all given instances or none of them to the trash. Their workloads are removed, they can be restored until the retention period ends.
+// @Description  If-Match is rejected, conditional deletes are only supported by DELETE /instances/{id}.
 // @Produce      json
 // @Security Bearer
 // @Param        id body []string true "IDs of the instances to delete"
@@ -132,6 +176,70 @@ func DeleteInstance() {} // for doc generation
 // @Router       /instances [DELETE]
 func DeleteInstances() {} // for doc generation
 
+// Query godoc
+// @Summary      Pause instance
+// @Description  Stops the workload of an instance without deleting it
+// @Security Bearer
+// @Param        id path string true "ID of the instance to pause"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/pause [POST]
+func PauseInstance() {} // for doc generation
+
+// Query godoc
+// @Summary      Resume instance
+// @Description  Starts the workload of a paused instance
+// @Security Bearer
+// @Param        id path string true "ID of the instance to resume"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/resume [POST]
+func ResumeInstance() {} // for doc generation
+
+// Query godoc
+// @Summary      Clone instance
+// @Description  Creates a new instance from an existing one. Fields set in the body replace the copied values. The custom mqtt password is only copied with write permission on the source.
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the instance to clone"
+// @Param        override body model.Instance false "fields to change, e.g. Name and Filter"
+// @Success      200 {object}  model.Instance
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      429
+// @Failure      500
+// @Router       /instances/{id}/clone [POST]
+func CloneInstance() {} // for doc generation
+
+// Query godoc
+// @Summary      Replay instance
+// @Description  Restarts the consumption of an instance from a point in time or from explicit partition offsets. The workload is redeployed with a new consumer group,
+// @Description  partitions without requested offset continue at their current position. Paused and unscheduled instances start at these offsets once they run again.
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the instance to replay"
+// @Param        replay body model.ReplayRequest true "either From or Offsets"
+// @Success      200 {object}  model.ReplayResult
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/replay [POST]
+func ReplayInstance() {} // for doc generation
+
 func DeploymentEndpoints(config config.Config, control Controller, router *httprouter.Router) {
 	resource := "/instances"
 
@@ -147,6 +255,24 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 			}
 			return
 		}
+		if isDryRun(request) {
+			result, err, code := control.ValidateCreateInstance(instance, getUserId(request), request.Header.Get(authHeader))
+			if err != nil {
+				http.Error(writer, err.Error(), code)
+				return
+			}
+			writeJson(writer, result)
+			return
+		}
+		if isAsync(request) {
+			operation, err, code := control.SubmitCreateInstance(instance, getUserId(request), request.Header.Get(authHeader))
+			if err != nil {
+				http.Error(writer, err.Error(), code)
+				return
+			}
+			writeOperation(writer, operation)
+			return
+		}
 		result, err, code := control.CreateInstance(instance, getUserId(request), request.Header.Get(authHeader))
 		if err != nil {
 			http.Error(writer, err.Error(), code)
@@ -192,7 +318,18 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 		search := request.URL.Query().Get("search")
 
 		includeGenerated := strings.ToLower(request.URL.Query().Get("generated")) != "false"
-		results, total, err, errCode := control.ListInstances(request.Header.Get(authHeader), limitInt, offsetInt, orderBy, asc, search, includeGenerated)
+
+		var expiresBefore *time.Time
+		if expiresWithin := request.URL.Query().Get("expiresWithin"); expiresWithin != "" {
+			duration, err := time.ParseDuration(expiresWithin)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+				return
+			}
+			t := time.Now().Add(duration)
+			expiresBefore = &t
+		}
+		results, total, err, errCode := control.ListInstances(request.Header.Get(authHeader), limitInt, offsetInt, orderBy, asc, search, includeGenerated, expiresBefore)
 		if err != nil {
 			http.Error(writer, err.Error(), errCode)
 			return
@@ -220,6 +357,7 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 			http.Error(writer, err.Error(), errCode)
 			return
 		}
+		writer.Header().Set("ETag", etag(result.Revision))
 		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
 		err = json.NewEncoder(writer).Encode(result)
 		if err != nil {
@@ -230,7 +368,12 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 
 	router.DELETE(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 		id := params.ByName("id")
-		err, errCode := control.DeleteInstances(request.Header.Get(authHeader), []string{id})
+		ifMatch, err, code := parseIfMatch(request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		err, errCode := control.DeleteInstance(request.Header.Get(authHeader), getUserId(request), id, ifMatch)
 		if err != nil {
 			http.Error(writer, err.Error(), errCode)
 			return
@@ -240,13 +383,17 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 	})
 
 	router.DELETE(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if request.Header.Get("If-Match") != "" {
+			http.Error(writer, "If-Match is only supported for the deletion of a single instance", http.StatusBadRequest)
+			return
+		}
 		var ids []string
 		err := json.NewDecoder(request.Body).Decode(&ids)
 		if err != nil {
 			http.Error(writer, err.Error(), http.StatusBadRequest)
 			return
 		}
-		err, errCode := control.DeleteInstances(request.Header.Get(authHeader), ids)
+		err, errCode := control.DeleteInstances(request.Header.Get(authHeader), getUserId(request), ids)
 		if err != nil {
 			http.Error(writer, err.Error(), errCode)
 			return
@@ -268,7 +415,30 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 			http.Error(writer, "IDs don't match", http.StatusBadRequest)
 			return
 		}
-		err, code := control.SetInstance(instance, getUserId(request), request.Header.Get(authHeader))
+		if isDryRun(request) {
+			result, err, code := control.ValidateSetInstance(instance, getUserId(request), request.Header.Get(authHeader))
+			if err != nil {
+				http.Error(writer, err.Error(), code)
+				return
+			}
+			writeJson(writer, result)
+			return
+		}
+		ifMatch, err, code := parseIfMatch(request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		if isAsync(request) {
+			operation, err, code := control.SubmitSetInstance(instance, getUserId(request), request.Header.Get(authHeader), ifMatch)
+			if err != nil {
+				http.Error(writer, err.Error(), code)
+				return
+			}
+			writeOperation(writer, operation)
+			return
+		}
+		err, code = control.SetInstance(instance, getUserId(request), request.Header.Get(authHeader), ifMatch)
 		if err != nil {
 			http.Error(writer, err.Error(), code)
 			return
@@ -277,6 +447,139 @@ func DeploymentEndpoints(config config.Config, control Controller, router *httpr
 		return
 	})
 
+	router.POST(resource+"/:id/pause", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		id := params.ByName("id")
+		err, errCode := control.PauseInstance(request.Header.Get(authHeader), getUserId(request), id)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+		return
+	})
+
+	router.PATCH(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		patch, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ifMatch, err, code := parseIfMatch(request)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		if isAsync(request) {
+			operation, err, code := control.SubmitPatchInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), patch, ifMatch)
+			if err != nil {
+				http.Error(writer, err.Error(), code)
+				return
+			}
+			writeOperation(writer, operation)
+			return
+		}
+		result, err, code := control.PatchInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), patch, ifMatch)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			return
+		}
+		writer.Header().Set("ETag", etag(result.Revision))
+		writeJson(writer, result)
+	})
+
+	router.POST(resource+"/:id/clone", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		override, err := io.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := control.CloneInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), override)
+		if err != nil {
+			http.Error(writer, err.Error(), code)
+			log.Println("ERROR: cant clone instance: ", err)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		writer.WriteHeader(code)
+		err = json.NewEncoder(writer).Encode(result)
+		if err != nil {
+			log.Println("ERROR: unable to encode response", err)
+		}
+	})
+
+	router.POST(resource+"/:id/replay", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		replay := model.ReplayRequest{}
+		err := json.NewDecoder(request.Body).Decode(&replay)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, errCode := control.ReplayInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), replay)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.POST(resource+"/:id/resume", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		id := params.ByName("id")
+		err, errCode := control.ResumeInstance(request.Header.Get(authHeader), getUserId(request), id)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+		return
+	})
+
+}
+
+// etag formats the revision of an instance as strong entity tag
+func etag(revision int64) string {
+	return "\"" + strconv.FormatInt(revision, 10) + "\""
+}
+
+// parseIfMatch returns the revisions accepted by the If-Match header, nil without header or for *.
+// Weak and foreign entity tags never match, because revisions are compared strongly.
+// A header without any matching candidate fails with 412, a malformed one with 400.
+func parseIfMatch(request *http.Request) (revisions []int64, err error, code int) {
+	value := strings.TrimSpace(request.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil, http.StatusOK
+	}
+	revisions = []int64{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || !strings.HasPrefix(tag, "\"") || !strings.HasSuffix(tag, "\"") || strings.Contains(tag[1:len(tag)-1], "\"") {
+			return nil, errors.New("malformed If-Match header"), http.StatusBadRequest
+		}
+		if weak {
+			continue
+		}
+		revision, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	if len(revisions) == 0 {
+		return nil, errors.New("If-Match does not match the current ETag"), http.StatusPreconditionFailed
+	}
+	return revisions, nil, http.StatusOK
+}
+
+func isDryRun(request *http.Request) bool {
+	return strings.ToLower(request.URL.Query().Get("dryRun")) == "true"
+}
+
+func isAsync(request *http.Request) bool {
+	return strings.ToLower(request.URL.Query().Get("async")) == "true"
 }
 
 func getUserId(request *http.Request) string {
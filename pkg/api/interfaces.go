@@ -17,13 +17,64 @@
 package api
 
 import (
+	"encoding/json"
+	"io"
+	"time"
+
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 )
 
 type Controller interface {
-	ListInstances(token string, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool) (results []model.Instance, total int, err error, errCode int)
+	ListInstances(token string, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, expiresBefore *time.Time) (results []model.Instance, total int, err error, errCode int)
 	ReadInstance(token string, id string) (result model.Instance, err error, errCode int)
+	GetInstanceLogs(token string, id string, options model.LogOptions) (logs io.ReadCloser, err error, errCode int)
 	CreateInstance(instance model.Instance, userId string, token string) (result model.Instance, err error, code int)
-	SetInstance(importType model.Instance, userId string, token string) (err error, code int)
-	DeleteInstances(token string, ids []string) (err error, errCode int)
+	CloneInstance(token string, userId string, id string, override json.RawMessage) (result model.Instance, err error, code int)
+	ExportInstances(token string) (result model.InstanceDefinitions, err error, code int)
+	ImportInstances(token string, userId string, definitions model.InstanceDefinitions, atomic bool) (report model.ImportReport, err error, code int)
+	SetInstance(importType model.Instance, userId string, token string, ifMatch []int64) (err error, code int)
+	PatchInstance(token string, userId string, id string, patch json.RawMessage, ifMatch []int64) (result model.Instance, err error, code int)
+	ValidateCreateInstance(instance model.Instance, userId string, token string) (result model.ValidationResult, err error, code int)
+	ValidateSetInstance(instance model.Instance, userId string, token string) (result model.ValidationResult, err error, code int)
+	DeleteInstances(token string, userId string, ids []string) (err error, errCode int)
+	DeleteInstance(token string, userId string, id string, ifMatch []int64) (err error, errCode int)
+	ListInstanceRevisions(token string, id string, limit int64, offset int64) (result []model.InstanceRevision, err error, code int)
+	DiffInstanceRevisions(token string, id string, from int64, to int64) (result model.InstanceDiff, err error, code int)
+	RollbackInstance(token string, userId string, id string, revision int64) (err error, code int)
+	ListDeletedInstances(token string, limit int64, offset int64) (results []model.Instance, err error, errCode int)
+	RestoreInstance(token string, userId string, id string) (err error, errCode int)
+	PurgeInstance(token string, userId string, id string) (err error, errCode int)
+	PauseInstance(token string, userId string, id string) (err error, errCode int)
+	ResumeInstance(token string, userId string, id string) (err error, errCode int)
+	ReplayInstance(token string, userId string, id string, request model.ReplayRequest) (result model.ReplayResult, err error, errCode int)
+	GetQuotaUsage(token string, userId string) (result model.QuotaUsage, err error, errCode int)
+	SubmitCreateInstance(instance model.Instance, userId string, token string) (result model.Operation, err error, code int)
+	SubmitSetInstance(instance model.Instance, userId string, token string, ifMatch []int64) (result model.Operation, err error, code int)
+	SubmitPatchInstance(token string, userId string, id string, patch json.RawMessage, ifMatch []int64) (result model.Operation, err error, code int)
+	GetOperation(token string, userId string, id string) (result model.Operation, err error, code int)
+	ListWebhooks(token string, userId string) (result []model.Webhook, err error, code int)
+	CreateWebhook(token string, userId string, webhook model.Webhook) (result model.Webhook, err error, code int)
+	DeleteWebhook(token string, userId string, id string) (err error, code int)
+	ListWebhookDeliveries(token string, userId string, id string, limit int64, offset int64) (result []model.WebhookDelivery, err error, code int)
+	TestWebhook(token string, userId string, id string) (result model.WebhookDelivery, err error, code int)
+
+	ListTemplates(token string, limit int64, offset int64) (results []model.Template, total int, err error, errCode int)
+	ReadTemplate(token string, id string) (result model.Template, err error, errCode int)
+	CreateTemplate(template model.Template, userId string, token string) (result model.Template, err error, code int)
+	SetTemplate(template model.Template, userId string, token string, propagate bool) (report model.TemplatePropagationReport, err error, code int)
+	DeleteTemplate(token string, userId string, id string) (err error, code int)
+	CreateInstanceFromTemplate(token string, userId string, templateId string, instance model.Instance) (result model.Instance, err error, code int)
+
+	DetectDrift(token string, userId string, redeploy bool) (report model.DriftReport, err error, errCode int)
+	CleanupOrphans(token string, dryRun bool) (report model.OrphanReport, err error, errCode int)
+	StartUpgrade(token string, userId string, options model.UpgradeOptions) (progress model.UpgradeProgress, err error, errCode int)
+	GetUpgradeProgress(token string) (progress model.UpgradeProgress, err error, errCode int)
+	CancelUpgrade(token string) (err error, errCode int)
+	ListCompensations(token string) (result []model.Compensation, err error, errCode int)
+	RetryCompensations(token string) (report model.CompensationReport, err error, errCode int)
+	ListAuditEvents(token string, query model.AuditQuery) (result []model.AuditEvent, err error, errCode int)
+	ListQuotas(token string) (result []model.Quota, err error, errCode int)
+	SetQuota(token string, userId string, quota model.Quota) (err error, errCode int)
+	RemoveQuota(token string, userId string, quotaType string, subject string) (err error, errCode int)
+	RecordPermissionChange(userId string, topic string, id string, permissions json.RawMessage, code int)
 }
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, LogEndpoints)
+}
+
+const defaultLogTail = 100
+
+// Query godoc
+// @Summary      Get instance logs
+// @Description  Provides the logs of the transfer container of an instance. With follow=true the response is streamed until the client disconnects.
+// @Produce      plain
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Param        tail query int false "number of lines from the end of the log, 0 for all lines, defaults to 100"
+// @Param        since query string false "only lines since this RFC3339 timestamp or duration (e.g. 15m)"
+// @Param        follow query bool false "stream new log lines"
+// @Param        timestamps query bool false "prefix lines with timestamps"
+// @Success      200 {string} string
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Failure      501
+// @Router       /instances/{id}/logs [GET]
+func GetInstanceLogs() {} // for doc generation
+
+func LogEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	router.GET("/instances/:id/logs", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		options, err := parseLogOptions(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logs, err, errCode := control.GetInstanceLogs(request.Header.Get(authHeader), params.ByName("id"), options)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		defer logs.Close()
+		go func() {
+			// unblocks reads of followed logs when the client disconnects
+			<-request.Context().Done()
+			_ = logs.Close()
+		}()
+
+		controller := http.NewResponseController(writer)
+		if options.Follow {
+			err = controller.SetWriteDeadline(time.Time{})
+			if err != nil {
+				log.Println("WARN: unable to remove write deadline for log stream", err)
+			}
+		}
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.WriteHeader(http.StatusOK)
+		buf := make([]byte, 4096)
+		for {
+			n, err := logs.Read(buf)
+			if n > 0 {
+				_, writeErr := writer.Write(buf[:n])
+				if writeErr != nil {
+					return
+				}
+				if options.Follow {
+					_ = controller.Flush()
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				if request.Context().Err() == nil {
+					log.Println("ERROR: unable to read instance logs", err)
+				}
+				return
+			}
+		}
+	})
+}
+
+func parseLogOptions(request *http.Request) (options model.LogOptions, err error) {
+	query := request.URL.Query()
+	options.Tail = defaultLogTail
+	if tail := query.Get("tail"); tail != "" {
+		options.Tail, err = strconv.Atoi(tail)
+		if err != nil {
+			return options, err
+		}
+	}
+	if since := query.Get("since"); since != "" {
+		options.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			duration, durationErr := time.ParseDuration(since)
+			if durationErr != nil {
+				return options, err
+			}
+			options.Since = time.Now().Add(-duration)
+			err = nil
+		}
+	}
+	options.Follow = strings.ToLower(query.Get("follow")) == "true"
+	options.Timestamps = strings.ToLower(query.Get("timestamps")) == "true"
+	return options, nil
+}
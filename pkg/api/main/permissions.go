@@ -33,7 +33,7 @@ func main() {
 		"api",
 		"permissions",
 		"../generated_permissions.go",
-		[]string{controller.Permv2topic},
+		[]string{controller.Permv2topic, controller.Permv2TemplateTopic},
 		api.ForwardPermissions,
 	)
 	if err != nil {
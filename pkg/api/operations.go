@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, OperationEndpoints)
+}
+
+// Query godoc
+// @Summary      Get operation
+// @Description  Reports status, result and error of an operation queued with async=true. Only the submitting user and admins can read an operation.
+// @Tags         operations
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the operation"
+// @Success      200 {object}  model.Operation
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /operations/{id} [GET]
+func GetOperation() {} // for doc generation
+
+func OperationEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/operations"
+
+	router.GET(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		result, err, errCode := control.GetOperation(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+}
+
+// writeOperation responds with a queued operation and its status location
+func writeOperation(writer http.ResponseWriter, operation model.Operation) {
+	writer.Header().Set("Location", "/operations/"+operation.Id)
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.WriteHeader(http.StatusAccepted)
+	err := json.NewEncoder(writer).Encode(operation)
+	if err != nil {
+		log.Println("ERROR: unable to encode response", err)
+	}
+}
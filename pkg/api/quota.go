@@ -0,0 +1,135 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, QuotaEndpoints)
+}
+
+// Query godoc
+// @Summary      Get quota usage
+// @Description  Compares the instances of the requesting user with their instance quota. A limit of 0 means unlimited.
+// @Tags         quota
+// @Produce      json
+// @Security Bearer
+// @Success      200 {object}  model.QuotaUsage
+// @Failure      401
+// @Failure      500
+// @Router       /quota [GET]
+func GetQuota() {} // for doc generation
+
+// Query godoc
+// @Summary      List quota overrides
+// @Description  Lists the instance quotas of users and roles which override default_instance_quota. Requires admin role.
+// @Tags         admin
+// @Produce      json
+// @Security Bearer
+// @Success      200 {array}  model.Quota
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/quotas [GET]
+func GetQuotas() {} // for doc generation
+
+// Query godoc
+// @Summary      Set quota override
+// @Description  Sets the instance quota of a user or role. A user quota wins over role quotas, of which the most generous applies. A limit of 0 means unlimited. Requires admin role.
+// @Tags         admin
+// @Accept       json
+// @Security Bearer
+// @Param        type path string true "user or role"
+// @Param        subject path string true "user id or role name"
+// @Param        quota body model.Quota true "only Limit is used"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/quotas/{type}/{subject} [PUT]
+func PutQuota() {} // for doc generation
+
+// Query godoc
+// @Summary      Remove quota override
+// @Description  Removes the instance quota of a user or role. Requires admin role.
+// @Tags         admin
+// @Security Bearer
+// @Param        type path string true "user or role"
+// @Param        subject path string true "user id or role name"
+// @Success      204
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/quotas/{type}/{subject} [DELETE]
+func DeleteQuota() {} // for doc generation
+
+func QuotaEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	router.GET("/quota", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		usage, err, errCode := control.GetQuotaUsage(request.Header.Get(authHeader), getUserId(request))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, usage)
+	})
+
+	resource := "/admin/quotas"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		quotas, err, errCode := control.ListQuotas(request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, quotas)
+	})
+
+	router.PUT(resource+"/:type/:subject", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		quota := model.Quota{}
+		err := json.NewDecoder(request.Body).Decode(&quota)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		quota.Type = params.ByName("type")
+		quota.Subject = params.ByName("subject")
+		err, errCode := control.SetQuota(request.Header.Get(authHeader), getUserId(request), quota)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+
+	router.DELETE(resource+"/:type/:subject", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.RemoveQuota(request.Header.Get(authHeader), getUserId(request), params.ByName("type"), params.ByName("subject"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, RevisionEndpoints)
+}
+
+// Query godoc
+// @Summary      List instance revisions
+// @Description  Lists the stored revisions of an instance, newest first. Secrets are masked.
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Param        limit query int false "default 100"
+// @Param        offset query int false "default 0"
+// @Success      200 {array}  model.InstanceRevision
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/revisions [GET]
+func GetInstanceRevisions() {} // for doc generation
+
+// Query godoc
+// @Summary      Diff instance revisions
+// @Description  Lists the configuration fields changed between two revisions. Secrets are masked.
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Param        revision path int true "revision to compare from"
+// @Param        to query int false "revision to compare to, defaults to the current version"
+// @Success      200 {object}  model.InstanceDiff
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/revisions/{revision}/diff [GET]
+func GetInstanceRevisionDiff() {} // for doc generation
+
+// Query godoc
+// @Summary      Roll back instance
+// @Description  Updates and redeploys the instance with the configuration of the given revision. The result is stored as a new revision.
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Param        revision path int true "revision to roll back to"
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /instances/{id}/revisions/{revision}/rollback [POST]
+func PostInstanceRollback() {} // for doc generation
+
+func RevisionEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/instances/:id/revisions"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		limit := request.URL.Query().Get("limit")
+		if limit == "" {
+			limit = "100"
+		}
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset := request.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		offsetInt, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		revisions, err, errCode := control.ListInstanceRevisions(request.Header.Get(authHeader), params.ByName("id"), limitInt, offsetInt)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, revisions)
+	})
+
+	router.GET(resource+"/:revision/diff", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		from, err := strconv.ParseInt(params.ByName("revision"), 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var to int64 = -1
+		if request.URL.Query().Has("to") {
+			to, err = strconv.ParseInt(request.URL.Query().Get("to"), 10, 64)
+			if err != nil || to < 0 {
+				http.Error(writer, "invalid revision to compare to", http.StatusBadRequest)
+				return
+			}
+		}
+		diff, err, errCode := control.DiffInstanceRevisions(request.Header.Get(authHeader), params.ByName("id"), from, to)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, diff)
+	})
+
+	router.POST(resource+"/:revision/rollback", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		revision, err := strconv.ParseInt(params.ByName("revision"), 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err, errCode := control.RollbackInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), revision)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+}
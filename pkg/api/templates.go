@@ -0,0 +1,240 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, TemplateEndpoints)
+}
+
+type templateList struct {
+	Templates []model.Template `json:"templates"`
+	Count     int              `json:"count"`
+	Total     int              `json:"total"`
+}
+
+// Query godoc
+// @Summary      List templates
+// @Description  Lists all templates the caller may read, CustomMqttPassword is masked
+// @Tags         templates
+// @Produce      json
+// @Security Bearer
+// @Param        limit query int false "default 100"
+// @Param        offset query int false "default 0"
+// @Success      200 {object}  templateList
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /templates [GET]
+func GetTemplates() {} // for doc generation
+
+// Query godoc
+// @Summary      Get template
+// @Description  Provides the template, CustomMqttPassword is masked
+// @Tags         templates
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the template"
+// @Success      200 {object}  model.Template
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /templates/{id} [GET]
+func GetTemplate() {} // for doc generation
+
+// Query godoc
+// @Summary      Create template
+// @Description  Creates a template with settings shared by multiple instances
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        template body model.Template true "Template to create"
+// @Success      200 {object}  model.Template
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /templates [POST]
+func PostTemplate() {} // for doc generation
+
+// Query godoc
+// @Summary      Update template
+// @Description  Updates a template. With propagate=true all linked instances the caller may write are updated and redeployed.
+// @Description  A CustomMqttPassword of "***", as returned by reads, keeps the stored password.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the template"
+// @Param        template body model.Template true "Template to update"
+// @Param        propagate query bool false "update linked instances"
+// @Success      200 {object}  model.TemplatePropagationReport
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /templates/{id} [PUT]
+func PutTemplate() {} // for doc generation
+
+// Query godoc
+// @Summary      Delete template
+// @Description  Deletes a template. Linked instances keep their settings.
+// @Tags         templates
+// @Security Bearer
+// @Param        id path string true "ID of the template"
+// @Success      204
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /templates/{id} [DELETE]
+func DeleteTemplate() {} // for doc generation
+
+// Query godoc
+// @Summary      Create instance from template
+// @Description  Creates an instance linked to the template. Offset, Values, custom broker settings and ImageVersion are taken from the template,
+// @Description  the body provides the instance specific fields like Name, FilterType, Filter and Topic.
+// @Description  The CustomMqttPassword of the template is only used if the caller may write the template, otherwise the one of the body is used.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the template"
+// @Param        instance body model.Instance true "instance specific fields"
+// @Success      200 {object}  model.Instance
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      429
+// @Failure      500
+// @Router       /templates/{id}/instances [POST]
+func PostTemplateInstance() {} // for doc generation
+
+func TemplateEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/templates"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		limit := request.URL.Query().Get("limit")
+		if limit == "" {
+			limit = "100"
+		}
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset := request.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		offsetInt, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results, total, err, errCode := control.ListTemplates(request.Header.Get(authHeader), limitInt, offsetInt)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, templateList{
+			Templates: results,
+			Count:     len(results),
+			Total:     total,
+		})
+	})
+
+	router.GET(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		result, err, errCode := control.ReadTemplate(request.Header.Get(authHeader), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.POST(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		template := model.Template{}
+		err := json.NewDecoder(request.Body).Decode(&template)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, errCode := control.CreateTemplate(template, getUserId(request), request.Header.Get(authHeader))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			log.Println("ERROR: cant create template: ", err)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.PUT(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		template := model.Template{}
+		err := json.NewDecoder(request.Body).Decode(&template)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if template.Id != params.ByName("id") {
+			http.Error(writer, "IDs don't match", http.StatusBadRequest)
+			return
+		}
+		propagate := strings.ToLower(request.URL.Query().Get("propagate")) == "true"
+		report, err, errCode := control.SetTemplate(template, getUserId(request), request.Header.Get(authHeader), propagate)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, report)
+	})
+
+	router.DELETE(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.DeleteTemplate(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+
+	router.POST(resource+"/:id/instances", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		instance := model.Instance{}
+		err := json.NewDecoder(request.Body).Decode(&instance)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, errCode := control.CreateInstanceFromTemplate(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), instance)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			log.Println("ERROR: cant create instance from template: ", err)
+			return
+		}
+		writeJson(writer, result)
+	})
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, DeletedInstanceEndpoints)
+}
+
+// Query godoc
+// @Summary      List deleted instances
+// @Description  Lists the instances in the trash the caller may read, most recently deleted first
+// @Tags         trash
+// @Produce      json
+// @Security Bearer
+// @Param        limit query int false "default 100"
+// @Param        offset query int false "default 0"
+// @Success      200 {array}  model.Instance
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /deleted-instances [GET]
+func GetDeletedInstances() {} // for doc generation
+
+// Query godoc
+// @Summary      Restore deleted instance
+// @Description  Redeploys an instance from the trash. The consumer group is kept, so the instance continues where it stopped.
+// @Tags         trash
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Success      204
+// @Failure      401
+// @Failure      404
+// @Failure      410 "retention period ended"
+// @Failure      429
+// @Failure      500
+// @Router       /deleted-instances/{id}/restore [POST]
+func PostRestoreInstance() {} // for doc generation
+
+// Query godoc
+// @Summary      Purge deleted instance
+// @Description  Permanently removes an instance from the trash before its retention period ends
+// @Tags         trash
+// @Security Bearer
+// @Param        id path string true "ID of the instance"
+// @Success      204
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /deleted-instances/{id} [DELETE]
+func DeleteDeletedInstance() {} // for doc generation
+
+func DeletedInstanceEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/deleted-instances"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		limit := request.URL.Query().Get("limit")
+		if limit == "" {
+			limit = "100"
+		}
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset := request.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		offsetInt, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		instances, err, errCode := control.ListDeletedInstances(request.Header.Get(authHeader), limitInt, offsetInt)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, instances)
+	})
+
+	router.POST(resource+"/:id/restore", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.RestoreInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+
+	router.DELETE(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.PurgeInstance(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+}
@@ -32,9 +32,10 @@ func (this *CorsMiddleware) ServeHTTP(res http.ResponseWriter, req *http.Request
 		origin = "*"
 	}
 	res.Header().Set("Access-Control-Allow-Origin", origin)
-	res.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, authorization, Authorization")
+	res.Header().Set("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, authorization, Authorization, If-Match")
+	res.Header().Set("Access-Control-Expose-Headers", "ETag, Location")
 	res.Header().Set("Access-Control-Allow-Credentials", "true")
-	res.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	res.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 
 	if req.Method == "OPTIONS" {
 		res.WriteHeader(http.StatusOK)
@@ -65,3 +65,8 @@ func (this *ResponseWriterWithStatusCodeLog) WriteHeader(statusCode int) {
 	this.Status = statusCode
 	this.Parent.WriteHeader(statusCode)
 }
+
+// Unwrap enables http.ResponseController to access the parent writer (e.g. to flush streamed responses)
+func (this *ResponseWriterWithStatusCodeLog) Unwrap() http.ResponseWriter {
+	return this.Parent
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+func init() {
+	endpoints = append(endpoints, WebhookEndpoints)
+}
+
+// Query godoc
+// @Summary      List webhooks
+// @Description  Lists the webhooks of the requesting user. Secrets are omitted.
+// @Tags         webhooks
+// @Produce      json
+// @Security Bearer
+// @Success      200 {array}  model.Webhook
+// @Failure      401
+// @Failure      500
+// @Router       /webhooks [GET]
+func GetWebhooks() {} // for doc generation
+
+// Query godoc
+// @Summary      Create webhook
+// @Description  Registers a webhook for deployment state changes of a single instance (InstanceId set, requires read permission) or of all own instances.
+// @Description  Notifications are posted as JSON with the headers X-Event, X-Delivery, X-Timestamp and X-Signature, which contains "sha256=" and the hex encoded HMAC-SHA256 of "<X-Timestamp>.<body>" keyed with the secret.
+// @Description  A secret is generated if none is given, it is only returned in this response. Failed deliveries are retried with exponential backoff.
+// @Description  Urls of loopback, private, link-local or other internal addresses are rejected, also if a host resolves to one of them on delivery.
+// @Description  Notifications are only sent while the user can read the instance. A webhook of a single instance is removed once the user lost read permission.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        webhook body model.Webhook true "Url and optionally InstanceId, States and Secret"
+// @Success      200 {object}  model.Webhook
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /webhooks [POST]
+func PostWebhook() {} // for doc generation
+
+// Query godoc
+// @Summary      Delete webhook
+// @Description  Removes a webhook of the requesting user and its delivery log.
+// @Tags         webhooks
+// @Security Bearer
+// @Param        id path string true "ID of the webhook"
+// @Success      204
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /webhooks/{id} [DELETE]
+func DeleteWebhook() {} // for doc generation
+
+// Query godoc
+// @Summary      List webhook deliveries
+// @Description  Lists the delivery log of a webhook of the requesting user, newest first.
+// @Tags         webhooks
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the webhook"
+// @Param        limit query int false "default 100"
+// @Param        offset query int false "default 0"
+// @Success      200 {array}  model.WebhookDelivery
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /webhooks/{id}/deliveries [GET]
+func GetWebhookDeliveries() {} // for doc generation
+
+// Query godoc
+// @Summary      Test webhook
+// @Description  Posts a test notification to a webhook of the requesting user once and returns the logged delivery. A failed delivery is reported in its Status, it is not retried.
+// @Tags         webhooks
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "ID of the webhook"
+// @Success      200 {object}  model.WebhookDelivery
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /webhooks/{id}/test [POST]
+func PostWebhookTest() {} // for doc generation
+
+func WebhookEndpoints(config config.Config, control Controller, router *httprouter.Router) {
+	resource := "/webhooks"
+
+	router.GET(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		result, err, errCode := control.ListWebhooks(request.Header.Get(authHeader), getUserId(request))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.POST(resource, func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		webhook := model.Webhook{}
+		err := json.NewDecoder(request.Body).Decode(&webhook)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, errCode := control.CreateWebhook(request.Header.Get(authHeader), getUserId(request), webhook)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.DELETE(resource+"/:id", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		err, errCode := control.DeleteWebhook(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writer.WriteHeader(errCode)
+	})
+
+	router.GET(resource+"/:id/deliveries", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		limit := request.URL.Query().Get("limit")
+		if limit == "" {
+			limit = "100"
+		}
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset := request.URL.Query().Get("offset")
+		if offset == "" {
+			offset = "0"
+		}
+		offsetInt, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, errCode := control.ListWebhookDeliveries(request.Header.Get(authHeader), getUserId(request), params.ByName("id"), limitInt, offsetInt)
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+
+	router.POST(resource+"/:id/test", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		result, err, errCode := control.TestWebhook(request.Header.Get(authHeader), getUserId(request), params.ByName("id"))
+		if err != nil {
+			http.Error(writer, err.Error(), errCode)
+			return
+		}
+		writeJson(writer, result)
+	})
+}
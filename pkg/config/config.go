@@ -52,7 +52,33 @@ type Config struct {
 	ImportDeployUrl           string `json:"import_deploy_url"`
 	AnalyticsPipelineUrl      string `json:"analytics_pipeline_url"`
 	StartupEnsureDeployed     bool   `json:"startup_ensure_deployed"`
+	ReconcileInterval         string `json:"reconcile_interval"`
+	ReconcileRedeployDrift    bool   `json:"reconcile_redeploy_drift"`
+	DeletedInstanceRetention  string `json:"deleted_instance_retention"` // restore window of deleted instances, empty keeps them until purged manually
 	PermissionsV2Url          string `json:"permissions_v2_url"`
+	NotificationUrl           string `json:"notification_url"`
+	ExpiryWarning             string `json:"expiry_warning"`             // notify owners this long before their instances expire, empty disables warnings
+	DefaultInstanceQuota      int64  `json:"default_instance_quota"`     // instances per user without quota override, 0 is unlimited
+	OperationWorkers          int64  `json:"operation_workers"`          // asynchronous operations processed in parallel by this replica
+	OperationRetention        string `json:"operation_retention"`        // finished operations are removed after this duration, empty keeps them
+	LifecycleTopic            string `json:"lifecycle_topic"`            // kafka topic of instance lifecycle events, empty disables them
+	WebhookCheckInterval      string `json:"webhook_check_interval"`     // deployment states are checked this often for webhook notifications, empty disables webhooks
+	WebhookDeliveryRetention  string `json:"webhook_delivery_retention"` // finished webhook deliveries are removed after this duration, empty keeps them
+
+	MongoCompensationCollection    string `json:"mongo_compensation_collection"` // failed rollback steps waiting for retry
+	MongoTemplateCollection        string `json:"mongo_template_collection"`
+	MongoHistoryCollection         string `json:"mongo_history_collection"`
+	MongoAuditCollection           string `json:"mongo_audit_collection"`
+	MongoQuotaCollection           string `json:"mongo_quota_collection"`     // quota overrides per user and role
+	MongoOperationCollection       string `json:"mongo_operation_collection"` // queue of asynchronous operations, holds the requests including custom mqtt passwords until they finished
+	MongoOutboxCollection          string `json:"mongo_outbox_collection"`    // lifecycle events waiting to be published
+	MongoWebhookCollection         string `json:"mongo_webhook_collection"`
+	MongoWebhookDeliveryCollection string `json:"mongo_webhook_delivery_collection"` // delivery log and retry queue of webhook notifications
+	MongoInstanceStateCollection   string `json:"mongo_instance_state_collection"`   // deployment states last notified to webhooks
+	MongoLockCollection            string `json:"mongo_lock_collection"`             // leases serializing quota checks and upgrades across replicas
+	MongoUpgradeCollection         string `json:"mongo_upgrade_collection"`          // progress of the current or last rolling upgrade
+
+	TransferImageVersions []string `json:"transfer_image_versions"` // allowed values of Instance.ImageVersion
 
 	Debug bool `json:"debug"`
 }
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// checkAdmin expects a token already verified by the api gateway
+func checkAdmin(token string) (err error, code int) {
+	parsed, err := jwt.Parse(token)
+	if err != nil {
+		return err, http.StatusUnauthorized
+	}
+	if !parsed.IsAdmin() {
+		return errors.New("access denied"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
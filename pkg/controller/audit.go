@@ -0,0 +1,91 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	"github.com/hashicorp/go-uuid"
+)
+
+func (this *Controller) ListAuditEvents(token string, query model.AuditQuery) (result []model.AuditEvent, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return nil, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	result, err = this.db.ListAuditEvents(ctx, query)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// RecordPermissionChange audits permission updates, which are forwarded to the permissions service without passing the controller.
+// code is the status code of the forwarded request.
+func (this *Controller) RecordPermissionChange(userId string, topic string, id string, permissions json.RawMessage, code int) {
+	var err error
+	if code >= http.StatusBadRequest {
+		err = errors.New(http.StatusText(code))
+	}
+	var value interface{}
+	_ = json.Unmarshal(permissions, &value)
+	changes := []model.FieldChange{{Field: "Permissions", To: value}}
+	switch topic {
+	case Permv2topic:
+		this.audit(model.AuditActionShare, userId, id, changes, err)
+	case Permv2TemplateTopic:
+		this.auditTemplate(model.AuditActionTemplateShare, userId, id, changes, err)
+	}
+}
+
+func (this *Controller) audit(action string, userId string, instanceId string, changes []model.FieldChange, err error) {
+	this.addAuditEvent(model.AuditEvent{Action: action, UserId: userId, InstanceId: instanceId, Changes: changes}, err)
+	this.addFailedLifecycleEvent(action, userId, instanceId, changes, err)
+}
+
+func (this *Controller) auditTemplate(action string, userId string, templateId string, changes []model.FieldChange, err error) {
+	this.addAuditEvent(model.AuditEvent{Action: action, UserId: userId, TemplateId: templateId, Changes: changes}, err)
+}
+
+// addAuditEvent stores the event with the outcome derived from err. Failures to store are logged only,
+// the audited call has already been executed.
+func (this *Controller) addAuditEvent(event model.AuditEvent, err error) {
+	event.Timestamp = time.Now()
+	event.Outcome = model.AuditOutcomeSuccess
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Error = err.Error()
+	}
+	id, idErr := uuid.GenerateUUID()
+	if idErr != nil {
+		log.Println("ERROR: unable to record audit event", event.Action, event.InstanceId, idErr)
+		return
+	}
+	event.Id = id
+	ctx, _ := util.GetTimeoutContext()
+	dbErr := this.db.AddAuditEvent(ctx, event)
+	if dbErr != nil {
+		log.Println("ERROR: unable to record audit event", event.Action, event.InstanceId, dbErr)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// CloneInstance creates a new instance from the source instance with the fields of override replacing the copied ones.
+// The custom mqtt password is only copied if the caller may write the source instance.
+func (this *Controller) CloneInstance(token string, userId string, id string, override json.RawMessage) (result model.Instance, err error, code int) {
+	ok, err, code := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Read)
+	if err != nil {
+		return result, err, code
+	}
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	canWrite, err, code := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	ctx, _ := getTimeoutContext()
+	source, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+
+	clone := source
+	clone.Id = ""
+	clone.ServiceId = ""
+	clone.Status = nil
+	clone.Paused = false
+	clone.Generated = false
+	clone.CreatedAt = time.Time{}
+	clone.UpdatedAt = time.Time{}
+	clone.Name = source.Name + " (copy)"
+	if !canWrite {
+		clone.CustomMqttPassword = nil
+	}
+	if len(override) > 0 {
+		err = json.Unmarshal(override, &clone)
+		if err != nil {
+			return result, err, http.StatusBadRequest
+		}
+	}
+	clone.TemplateId = "" // linking requires read permission on the template, see CreateInstanceFromTemplate
+	return this.createInstance(clone, userId, token, tokenRoles(token, userId), model.AuditActionClone, "")
+}
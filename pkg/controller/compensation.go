@@ -0,0 +1,318 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	"github.com/hashicorp/go-uuid"
+)
+
+func (this *Controller) ListCompensations(token string) (result []model.Compensation, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return nil, err, code
+	}
+	result = []model.Compensation{}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		compensations, err := this.db.ListCompensations(ctx, batchSize, offset)
+		if err != nil {
+			return nil, err, http.StatusInternalServerError
+		}
+		offset += int64(len(compensations))
+		for _, compensation := range compensations {
+			if compensation.Instance != nil {
+				maskInstance(compensation.Instance)
+			}
+			result = append(result, compensation)
+		}
+		if len(compensations) < int(batchSize) {
+			return result, nil, http.StatusOK
+		}
+	}
+}
+
+func (this *Controller) RetryCompensations(token string) (report model.CompensationReport, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return report, err, code
+	}
+	report, err = this.retryCompensations()
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	return report, nil, http.StatusOK
+}
+
+// retryCompensations runs all recorded compensations once. Resolved ones are removed, failed ones stay for the next run.
+func (this *Controller) retryCompensations() (report model.CompensationReport, err error) {
+	report = model.CompensationReport{Resolved: []string{}, Failed: map[string]string{}}
+	ctx, _ := util.GetTimeoutContext()
+	// compensations are removed while iterating, so a single snapshot is used instead of paging
+	compensations, err := this.db.ListCompensations(ctx, 0, 0)
+	if err != nil {
+		return report, err
+	}
+	for _, compensation := range compensations {
+		if this.ctx.Err() != nil {
+			return report, this.ctx.Err()
+		}
+		report.Retried++
+		unlock := this.instanceLocks.Lock(compensation.InstanceId)
+		err = this.retryCompensation(compensation)
+		unlock()
+		if err != nil {
+			report.Failed[compensation.Id] = err.Error()
+			continue
+		}
+		report.Resolved = append(report.Resolved, compensation.Id)
+	}
+	return report, nil
+}
+
+// retryCompensation runs a recorded compensation and removes it on success or stores the failure otherwise.
+// The caller has to hold the lock of the instance.
+func (this *Controller) retryCompensation(compensation model.Compensation) (err error) {
+	err = this.runCompensation(compensation)
+	ctx, _ := util.GetTimeoutContext()
+	if err == nil {
+		return this.db.RemoveCompensation(ctx, compensation.Id)
+	}
+	compensation.Attempts++
+	compensation.LastError = err.Error()
+	compensation.UpdatedAt = time.Now()
+	storeErr := this.db.SetCompensation(ctx, compensation)
+	if storeErr != nil {
+		log.Println("ERROR: unable to update compensation", compensation.Id, storeErr)
+	}
+	return err
+}
+
+// compensate runs a rollback step and records it for retries if it fails.
+// The caller has to hold the lock of the instance.
+func (this *Controller) compensate(compensation model.Compensation) {
+	err := this.runCompensation(compensation)
+	if err == nil {
+		return
+	}
+	log.Println("ERROR: unable to", compensation.Action, compensation.InstanceId+", recording compensation:", err)
+	compensation.Attempts = 1
+	compensation.LastError = err.Error()
+	this.recordCompensation(compensation)
+}
+
+func (this *Controller) recordCompensation(compensation model.Compensation) {
+	err := this.newCompensation(&compensation)
+	if err == nil {
+		ctx, _ := util.GetTimeoutContext()
+		err = this.db.SetCompensation(ctx, compensation)
+	}
+	if err != nil {
+		log.Println("ERROR: unable to record compensation", compensation.Action, compensation.InstanceId, compensation.ServiceId, err)
+	}
+}
+
+func (this *Controller) newCompensation(compensation *model.Compensation) error {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	compensation.Id = id
+	compensation.CreatedAt = time.Now()
+	compensation.UpdatedAt = compensation.CreatedAt
+	return nil
+}
+
+func (this *Controller) runCompensation(compensation model.Compensation) (err error) {
+	switch compensation.Action {
+	case model.CompensationRemoveInstance:
+		ctx, _ := util.GetTimeoutContext()
+		err = this.db.RemoveInstances(ctx, []string{compensation.InstanceId})
+		if err != nil {
+			return err
+		}
+		err = this.db.RemoveInstanceRevisions(ctx, []string{compensation.InstanceId})
+		if err != nil {
+			return err
+		}
+		return this.removeWorkloadAndPermissions(compensation)
+	case model.CompensationCleanupDeleted:
+		ctx, _ := util.GetTimeoutContext()
+		remaining, _, err := this.db.GetInstances(ctx, []string{compensation.InstanceId})
+		if err != nil {
+			return err
+		}
+		trashed, _, err := this.db.GetDeletedInstances(ctx, []string{compensation.InstanceId})
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 || len(trashed) > 0 {
+			return nil // the deletion has not been committed, nothing to clean up
+		}
+		return this.removeWorkloadAndPermissions(compensation)
+	case model.CompensationRestoreInstance:
+		return this.restoreInstance(compensation)
+	case model.CompensationRemoveWorkload:
+		ctx, _ := util.GetTimeoutContext()
+		active, _, err := this.db.GetInstances(ctx, []string{compensation.InstanceId})
+		if err != nil {
+			return err
+		}
+		if len(active) > 0 {
+			return nil // restored in the meantime, the workload is in use again
+		}
+		return this.removeWorkload(compensation.ServiceId)
+	default:
+		return errors.New("unknown compensation action " + compensation.Action)
+	}
+}
+
+func (this *Controller) removeWorkloadAndPermissions(compensation model.Compensation) error {
+	err := this.removeWorkload(compensation.ServiceId)
+	if err != nil {
+		return err
+	}
+	err, code := this.permv2.RemoveResource(permv2.InternalAdminToken, Permv2topic, compensation.InstanceId)
+	if err != nil && code != http.StatusNotFound {
+		return err
+	}
+	return nil
+}
+
+func (this *Controller) removeWorkload(serviceId string) error {
+	if serviceId == "" {
+		return nil
+	}
+	exists, err := this.deploymentClient.ContainerExists(serviceId)
+	if err != nil || !exists {
+		return err
+	}
+	return this.deploymentClient.RemoveContainer(serviceId)
+}
+
+// restoreInstance rolls back a failed update, unless the instance has been changed or removed in the meantime
+func (this *Controller) restoreInstance(compensation model.Compensation) error {
+	if compensation.Instance == nil {
+		return errors.New("missing previous instance version")
+	}
+	ctx, _ := util.GetTimeoutContext()
+	current, _, err := this.db.GetInstances(ctx, []string{compensation.InstanceId})
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 || current[0].Revision != compensation.Instance.Revision {
+		return nil // removed or updated in the meantime
+	}
+	previous := *compensation.Instance
+	previous.ServiceId = compensation.ServiceId
+	err = this.restoreDeployment(&previous, compensation.Image, compensation.KafkaGroupId)
+	if err != nil {
+		return err
+	}
+	err = this.storeInstanceChange(&previous, "", current[0])
+	if errors.Is(err, errRevisionConflict) {
+		this.redeployStored(previous, compensation.KafkaGroupId)
+		return nil // updated in the meantime
+	}
+	return err
+}
+
+// rollbackUpdate restores the previous version of an instance after a failed update.
+// serviceId is the currently deployed workload, which may already belong to the new version.
+func (this *Controller) rollbackUpdate(previous model.Instance, serviceId string, image string, kafkaGroupId string) {
+	restored := previous
+	restored.ServiceId = serviceId
+	err := this.restoreDeployment(&restored, image, kafkaGroupId)
+	if err == nil {
+		// the record is written even if the service id did not change, it has to match the restored workload
+		err = this.storeInstanceChange(&restored, "", previous)
+		if errors.Is(err, errRevisionConflict) {
+			this.redeployStored(restored, kafkaGroupId)
+			return
+		}
+		if err == nil {
+			return
+		}
+	}
+	log.Println("ERROR: unable to roll back update of", previous.Id+", recording compensation:", err)
+	this.recordCompensation(model.Compensation{
+		Action:       model.CompensationRestoreInstance,
+		InstanceId:   previous.Id,
+		ServiceId:    restored.ServiceId,
+		Image:        image,
+		KafkaGroupId: kafkaGroupId,
+		Instance:     &previous,
+		Attempts:     1,
+		LastError:    err.Error(),
+	})
+}
+
+// deployedVersion returns image and consumer group of the current workload of the instance,
+// falling back to the configured image if the workload can not be inspected.
+// The consumer group is only taken from the workload for records stored before it was tracked in the instance.
+func (this *Controller) deployedVersion(instance model.Instance) (image string, kafkaGroupId string) {
+	kafkaGroupId = instance.KafkaGroupId
+	deployed, exists, err := this.deploymentClient.GetContainerConfig(instance.ServiceId)
+	if err != nil || !exists || deployed.Image == "" {
+		return this.getImage(instance), kafkaGroupId
+	}
+	if groupId := deployed.Env["KAFKA_GROUP_ID"]; kafkaGroupId == "" && strings.HasPrefix(groupId, instance.Id) {
+		kafkaGroupId = groupId
+	}
+	return deployed.Image, kafkaGroupId
+}
+
+// restoreDeployment deploys the instance with the given image and consumer group.
+// The current workload is replaced if it exists, otherwise a new one is created.
+func (this *Controller) restoreDeployment(instance *model.Instance, image string, kafkaGroupId string) error {
+	env, err, _ := this.getEnv(instance, "", instance.UserId, false)
+	if err != nil {
+		return err
+	}
+	if kafkaGroupId != "" {
+		instance.KafkaGroupId = kafkaGroupId
+		env["KAFKA_GROUP_ID"] = kafkaGroupId
+	}
+	exists := false
+	if instance.ServiceId != "" {
+		exists, err = this.deploymentClient.ContainerExists(instance.ServiceId)
+		if err != nil {
+			return err
+		}
+	}
+	if exists {
+		return this.redeploy(instance, image, env)
+	}
+	instance.ServiceId, err = this.deploymentClient.CreateContainer(containerName(*instance), image, instance.UserId, env, true)
+	if err != nil {
+		return err
+	}
+	if !shouldRun(*instance) {
+		return this.deploymentClient.StopContainer(instance.ServiceId)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+// errRevisionConflict reports that the stored instance has been changed since it was loaded,
+// e.g. by another manager replica which is not serialized by the instance locks of this process
+var errRevisionConflict = errors.New("instance has been modified, reload it and retry")
+
+// replaceInstance replaces the stored instance if it still has the given revision
+func (this *Controller) replaceInstance(ctx context.Context, instance model.Instance, revision int64) error {
+	matched, err := this.db.ReplaceInstance(ctx, instance, revision)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return errRevisionConflict
+	}
+	return nil
+}
+
+// storeInstanceChange stores a change of the loaded instance as its next revision.
+// It fails with errRevisionConflict if the stored instance has been changed since it was loaded.
+func (this *Controller) storeInstanceChange(instance *model.Instance, userId string, loaded model.Instance) error {
+	instance.Revision = loaded.Revision + 1
+	return this.storeInstance(*instance, userId, &loaded)
+}
+
+// storeRunState stores a change of the paused or schedule state after the workload has been started or stopped.
+// If it is rejected with errRevisionConflict, the workload is brought back to the run state of the stored version.
+func (this *Controller) storeRunState(instance model.Instance, userId string, loaded model.Instance) error {
+	err := this.storeInstanceChange(&instance, userId, loaded)
+	if !errors.Is(err, errRevisionConflict) {
+		return err
+	}
+	ctx, _ := getTimeoutContext()
+	stored, exists, revertErr := this.db.GetInstance(ctx, instance.Id)
+	if revertErr == nil && exists {
+		revertErr = this.applyRunState(instance, stored)
+	}
+	if revertErr != nil {
+		log.Println("ERROR: unable to apply run state of stored version of", instance.Id, "after conflicting update:", revertErr)
+	}
+	return err
+}
+
+func storeErrorCode(err error) int {
+	if errors.Is(err, errRevisionConflict) {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+// redeployStored deploys the stored version of the instance after an update was rejected with errRevisionConflict,
+// because its workload has already been replaced with the rejected version
+func (this *Controller) redeployStored(rejected model.Instance, kafkaGroupId string) {
+	ctx, _ := getTimeoutContext()
+	stored, exists, err := this.db.GetInstance(ctx, rejected.Id)
+	if !exists && err == nil {
+		return // deleted in the meantime, the orphaned workload is left to the orphan cleanup
+	}
+	updated := stored
+	if err == nil {
+		updated.ServiceId = rejected.ServiceId
+		if stored.KafkaGroupId != "" {
+			kafkaGroupId = stored.KafkaGroupId
+		}
+		err = this.restoreDeployment(&updated, this.getImage(stored), kafkaGroupId)
+	}
+	if err == nil {
+		err = this.storeInstanceChange(&updated, "", stored)
+	}
+	if err != nil {
+		log.Println("ERROR: unable to deploy stored version of", rejected.Id, "after conflicting update, left to drift detection:", err)
+	}
+}
@@ -20,34 +20,63 @@ import (
 	"context"
 	"net/http"
 	"slices"
+	"sync"
 	"time"
 
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	k2mmodel "github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/verification"
 	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/hashicorp/go-uuid"
 )
 
 type Controller struct {
+	ctx              context.Context
+	wg               *sync.WaitGroup
 	db               Database
 	deploymentClient DeploymentClient
 	config           config.Config
 	verifier         *verification.Verifier
 	permv2           permv2.Client
+	kafka            KafkaClient
+	instanceLocks    *keyedMutex
+	quotaLocks       *keyedMutex // per user, serializes quota checks with the creation they allow within this process, see lockQuota
+	lockOwner        string      // identifies the locks of this process shared with other replicas
+	operationQueued  chan struct{}
+	lifecycleQueued  chan struct{}
+	webhookQueued    chan struct{}
+	upgradeMux       sync.Mutex
+	upgrade          *k2mmodel.UpgradeProgress
+	upgradeCancel    context.CancelFunc
 }
 
 const Permv2topic = "kafka2mqtt"
+const Permv2TemplateTopic = "kafka2mqtt_templates"
 
-func New(config config.Config, db Database, deploymentClient DeploymentClient, verifier *verification.Verifier, permv2 permv2.Client) (*Controller, error) {
+func New(config config.Config, ctx context.Context, wg *sync.WaitGroup, db Database, deploymentClient DeploymentClient, verifier *verification.Verifier, permv2 permv2.Client, kafka KafkaClient) (*Controller, error) {
+	lockOwner, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
 	controller := &Controller{
+		ctx:              ctx,
+		wg:               wg,
 		db:               db,
 		deploymentClient: deploymentClient,
 		config:           config,
 		verifier:         verifier,
 		permv2:           permv2,
+		kafka:            kafka,
+		instanceLocks:    newKeyedMutex(),
+		quotaLocks:       newKeyedMutex(),
+		lockOwner:        lockOwner,
+		operationQueued:  make(chan struct{}, 1),
+		lifecycleQueued:  make(chan struct{}, 1),
+		webhookQueued:    make(chan struct{}, 1),
 	}
 
-	err := controller.migrate()
+	err = controller.migrate()
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +100,28 @@ func (c *Controller) migrate() error {
 	if err != nil {
 		return err
 	}
+	_, err, _ = c.permv2.SetTopic(permv2.InternalAdminToken, permv2.Topic{
+		Id: Permv2TemplateTopic,
+		DefaultPermissions: model.ResourcePermissions{
+			RolePermissions: map[string]model.PermissionsMap{
+				"admin": {
+					Read:         true,
+					Write:        true,
+					Execute:      true,
+					Administrate: true,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
 	var offset int64 = 0
 	var batchSize int64 = 100
 	dbInstanceIds := []string{}
 	for {
 		ctx, _ := getTimeoutContext()
-		instances, err := c.db.ListInstances(ctx, batchSize, offset, "name", true, "", true, nil)
+		instances, err := c.db.ListInstances(ctx, batchSize, offset, "name", true, "", true, nil, nil)
 		if err != nil {
 			return err
 		}
@@ -116,6 +161,22 @@ func (c *Controller) migrate() error {
 			break // done
 		}
 	}
+	// permissions of deleted instances are kept until they are purged
+	offset = 0
+	for {
+		ctx, _ := getTimeoutContext()
+		instances, err := c.db.ListDeletedInstances(ctx, batchSize, offset, nil, nil)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			dbInstanceIds = append(dbInstanceIds, instance.Id)
+		}
+		if len(instances) < int(batchSize) {
+			break // done
+		}
+	}
 	permv2Ids, err, _ := c.permv2.AdminListResourceIds(permv2.InternalAdminToken, Permv2topic, model.ListOptions{})
 	if err != nil {
 		return err
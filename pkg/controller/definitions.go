@@ -0,0 +1,298 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// ExportInstances returns the definitions of all instances the caller may read, generated instances are excluded
+func (this *Controller) ExportInstances(token string) (result model.InstanceDefinitions, err error, code int) {
+	ids, err, code := this.permv2.ListAccessibleResourceIds(token, Permv2topic, permv2.ListOptions{}, permv2.Read)
+	if err != nil {
+		return result, err, code
+	}
+	instances, err := this.listInstancesByIds(ids, false)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	result.Instances = []model.InstanceDefinition{}
+	for _, instance := range instances {
+		result.Instances = append(result.Instances, definitionOf(instance))
+	}
+	return result, nil, http.StatusOK
+}
+
+type importItem struct {
+	instance model.Instance
+	previous *model.Instance // nil if the instance is created
+	result   *model.ImportItemResult
+}
+
+// ImportInstances creates or updates instances from definitions. Definitions are matched by name with the instances the caller may write.
+// Creates and updates use the regular verification. In atomic mode, all definitions are validated before any change is made
+// and already applied items are rolled back if a later one fails.
+func (this *Controller) ImportInstances(token string, userId string, definitions model.InstanceDefinitions, atomic bool) (report model.ImportReport, err error, code int) {
+	ids, err, code := this.permv2.ListAccessibleResourceIds(token, Permv2topic, permv2.ListOptions{}, permv2.Write)
+	if err != nil {
+		return report, err, code
+	}
+	existing, err := this.listInstancesByIds(ids, false)
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	byName := map[string][]model.Instance{}
+	for _, instance := range existing {
+		byName[instance.Name] = append(byName[instance.Name], instance)
+	}
+
+	report.Items = make([]model.ImportItemResult, len(definitions.Instances))
+	items := []importItem{}
+	seen := map[string]bool{}
+	failed := false
+	for i, definition := range definitions.Instances {
+		result := &report.Items[i]
+		result.Name = definition.Name
+		switch {
+		case definition.Name == "":
+			result.Action, result.Error = model.ImportActionFailed, "missing name"
+		case seen[definition.Name]:
+			result.Action, result.Error = model.ImportActionFailed, "duplicate name in document"
+		case len(byName[definition.Name]) > 1:
+			result.Action, result.Error = model.ImportActionFailed, "ambiguous name, multiple instances exist"
+		}
+		seen[definition.Name] = true
+		if result.Action == model.ImportActionFailed {
+			failed = true
+			continue
+		}
+		item := importItem{result: result}
+		if matches := byName[definition.Name]; len(matches) == 1 {
+			previous := matches[0]
+			item.previous = &previous
+			item.instance = previous
+			applyDefinition(&item.instance, definition)
+			result.InstanceId = previous.Id
+			if reflect.DeepEqual(definitionOf(previous), definitionOf(item.instance)) && equalPtr(previous.CustomMqttPassword, item.instance.CustomMqttPassword) {
+				result.Action = model.ImportActionUnchanged
+				continue
+			}
+		} else {
+			applyDefinition(&item.instance, definition)
+		}
+		items = append(items, item)
+	}
+
+	if atomic {
+		code = http.StatusOK
+		for _, item := range items {
+			validation, err, validationCode := this.validateImportItem(item, userId, token)
+			if err == nil && !validation.Valid {
+				err = errors.New(strings.Join(validation.Errors, "; "))
+				validationCode = http.StatusBadRequest
+			}
+			if err != nil {
+				item.result.Action, item.result.Error = model.ImportActionFailed, err.Error()
+				failed = true
+				if code == http.StatusOK {
+					code = validationCode
+				}
+			}
+		}
+		if !failed {
+			// rejects the import before anything is applied, each creation is checked again under the quota lock
+			err, quotaCode := this.checkQuota(tokenRoles(token, userId), userId, countCreates(items))
+			if err != nil {
+				for _, item := range items {
+					if item.previous == nil {
+						item.result.Action, item.result.Error = model.ImportActionFailed, err.Error()
+					}
+				}
+				failed = true
+				code = quotaCode
+			}
+		}
+		if failed {
+			if code == http.StatusOK {
+				code = http.StatusBadRequest
+			}
+			markSkipped(items)
+			return report, nil, code
+		}
+	}
+
+	for i, item := range items {
+		err, itemCode := this.applyImportItem(item, userId, token)
+		if err == nil {
+			continue
+		}
+		item.result.Action, item.result.Error = model.ImportActionFailed, err.Error()
+		if atomic {
+			this.rollbackImport(items[:i], userId, token)
+			markSkipped(items[i+1:])
+			return report, nil, itemCode
+		}
+	}
+	report.Applied = true
+	return report, nil, http.StatusOK
+}
+
+func (this *Controller) validateImportItem(item importItem, userId string, token string) (result model.ValidationResult, err error, code int) {
+	if item.previous == nil {
+		return this.ValidateCreateInstance(item.instance, userId, token)
+	}
+	return this.ValidateSetInstance(item.instance, userId, token)
+}
+
+func (this *Controller) applyImportItem(item importItem, userId string, token string) (err error, code int) {
+	if item.previous == nil {
+		created, err, code := this.CreateInstance(item.instance, userId, token)
+		if err != nil {
+			return err, code
+		}
+		item.result.Action, item.result.InstanceId = model.ImportActionCreated, created.Id
+		return nil, code
+	}
+	err, code = this.SetInstance(item.instance, userId, token, []int64{item.previous.Revision}) // the definition was compared with this revision
+	if err != nil {
+		return err, code
+	}
+	item.result.Action = model.ImportActionUpdated
+	return nil, code
+}
+
+func (this *Controller) rollbackImport(applied []importItem, userId string, token string) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		item := applied[i]
+		var err error
+		switch {
+		case item.result.Action == model.ImportActionCreated:
+			err, _ = this.purgeInstances([]string{item.result.InstanceId}, userId, false)
+			this.audit(model.AuditActionPurge, userId, item.result.InstanceId, nil, err)
+		case item.result.Action == model.ImportActionUpdated:
+			err, _ = this.SetInstance(*item.previous, userId, token, nil)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Println("ERROR: unable to roll back import of", item.result.Name, err)
+			item.result.Error = "rollback failed: " + err.Error()
+			continue
+		}
+		item.result.Action = model.ImportActionRolledBack
+	}
+}
+
+func countCreates(items []importItem) (count int64) {
+	for _, item := range items {
+		if item.previous == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func markSkipped(items []importItem) {
+	for _, item := range items {
+		if item.result.Action == "" {
+			item.result.Action = model.ImportActionSkipped
+		}
+	}
+}
+
+func (this *Controller) listInstancesByIds(ids []string, includeGenerated bool) (result []model.Instance, err error) {
+	result = []model.Instance{}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "name", true, "", includeGenerated, ids, nil)
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(len(instances))
+		result = append(result, instances...)
+		if len(instances) < int(batchSize) {
+			return result, nil
+		}
+	}
+}
+
+func definitionOf(instance model.Instance) model.InstanceDefinition {
+	if len(instance.Values) == 0 {
+		instance.Values = nil
+	}
+	return model.InstanceDefinition{
+		Name:                instance.Name,
+		Description:         instance.Description,
+		EntityName:          instance.EntityName,
+		ServiceName:         instance.ServiceName,
+		FilterType:          instance.FilterType,
+		Filter:              instance.Filter,
+		Topic:               instance.Topic,
+		Offset:              instance.Offset,
+		Values:              instance.Values,
+		CustomMqttBroker:    instance.CustomMqttBroker,
+		CustomMqttUser:      instance.CustomMqttUser,
+		CustomMqttBaseTopic: instance.CustomMqttBaseTopic,
+		ImageVersion:        instance.ImageVersion,
+		Schedule:            instance.Schedule,
+		ExpiresAt:           instance.ExpiresAt,
+		ExpiryAction:        instance.ExpiryAction,
+	}
+}
+
+// applyDefinition overwrites the portable fields of the instance. The stored password is kept
+// if the definition contains none and the custom broker is unchanged.
+func applyDefinition(instance *model.Instance, definition model.InstanceDefinition) {
+	password := definition.CustomMqttPassword
+	if password == nil && equalPtr(instance.CustomMqttBroker, definition.CustomMqttBroker) {
+		password = instance.CustomMqttPassword
+	}
+	instance.Name = definition.Name
+	instance.Description = definition.Description
+	instance.EntityName = definition.EntityName
+	instance.ServiceName = definition.ServiceName
+	instance.FilterType = definition.FilterType
+	instance.Filter = definition.Filter
+	instance.Topic = definition.Topic
+	instance.Offset = definition.Offset
+	instance.Values = definition.Values
+	instance.CustomMqttBroker = definition.CustomMqttBroker
+	instance.CustomMqttUser = definition.CustomMqttUser
+	instance.CustomMqttPassword = password
+	instance.CustomMqttBaseTopic = definition.CustomMqttBaseTopic
+	instance.ImageVersion = definition.ImageVersion
+	instance.Schedule = definition.Schedule
+	instance.ExpiresAt = definition.ExpiresAt
+	instance.ExpiryAction = definition.ExpiryAction
+}
+
+func equalPtr[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+// json fields of model.Instance and model.Template which are managed by the service and not part of the configuration
+var nonConfigFields = []string{"ID", "CreatedAt", "UpdatedAt", "Status", "Revision", "Paused", "DeletedAt", "KafkaGroupId", "ScheduleState", "ExpiryWarnedAt"}
+
+var secretFields = []string{"CustomMqttPassword"}
+
+// diffFields lists the changed configuration fields of two instances or templates by their json name. Secrets are masked.
+func diffFields(from interface{}, to interface{}) (changes []model.FieldChange) {
+	changes = []model.FieldChange{}
+	fromFields := jsonFields(from)
+	toFields := jsonFields(to)
+	keys := []string{}
+	for key := range fromFields {
+		keys = append(keys, key)
+	}
+	for key := range toFields {
+		if _, ok := fromFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if slices.Contains(nonConfigFields, key) || reflect.DeepEqual(fromFields[key], toFields[key]) {
+			continue
+		}
+		change := model.FieldChange{Field: key, From: fromFields[key], To: toFields[key]}
+		if slices.Contains(secretFields, key) {
+			change.From, change.To = maskField(change.From), maskField(change.To)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func jsonFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(b, &fields)
+	return fields
+}
+
+func maskField(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return "***"
+}
+
+func maskInstance(instance *model.Instance) {
+	if instance.CustomMqttPassword != nil {
+		masked := "***"
+		instance.CustomMqttPassword = &masked
+	}
+}
+
+func maskTemplate(template *model.Template) {
+	if template.CustomMqttPassword != nil {
+		masked := "***"
+		template.CustomMqttPassword = &masked
+	}
+}
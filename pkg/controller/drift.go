@@ -0,0 +1,190 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// all variables getEnv may set; deployed variables not in this list (e.g. PATH from the image) are ignored
+var workerEnvKeys = []string{
+	"KAFKA_BOOTSTRAP",
+	"KAFKA_TOPIC",
+	"KAFKA_GROUP_ID",
+	"KAFKA_OFFSET",
+	"FILTER_QUERY",
+	"MQTT_BROKER",
+	"MQTT_USER",
+	"MQTT_PW",
+	"MQTT_CLIENT_ID",
+	"MQTT_QOS",
+	"MQTT_TOPIC_MAPPING",
+	"DEBUG",
+}
+
+var secretEnvKeys = []string{"MQTT_PW"}
+
+func maskEnvValue(key string, value string) string {
+	if value != "" && slices.Contains(secretEnvKeys, key) {
+		return "***"
+	}
+	return value
+}
+
+func (this *Controller) DetectDrift(token string, userId string, redeploy bool) (report model.DriftReport, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return report, err, code
+	}
+	report, err = this.detectDrift(redeploy)
+	if redeploy {
+		for _, drift := range report.Drifted {
+			if drift.Redeployed || drift.Error != "" {
+				var driftErr error
+				if drift.Error != "" {
+					driftErr = errors.New(drift.Error)
+				}
+				this.audit(model.AuditActionDriftRedeploy, userId, drift.InstanceId, nil, driftErr)
+			}
+		}
+	}
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	return report, nil, http.StatusOK
+}
+
+func (this *Controller) detectDrift(redeploy bool) (report model.DriftReport, err error) {
+	report.Drifted = []model.InstanceDrift{}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "name", true, "", true, nil, nil)
+		if err != nil {
+			return report, err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			if this.ctx.Err() != nil {
+				return report, this.ctx.Err()
+			}
+			report.Checked++
+			drift, drifted := this.checkDrift(instance.Id, redeploy)
+			if drifted {
+				report.Drifted = append(report.Drifted, drift)
+			}
+		}
+		if len(instances) < int(batchSize) {
+			return report, nil // done
+		}
+	}
+}
+
+// checkDrift compares the configuration getEnv renders today with the deployed one.
+// Missing workloads are reported but not redeployed, recreating them is the job of the reconciliation.
+func (this *Controller) checkDrift(id string, redeploy bool) (drift model.InstanceDrift, drifted bool) {
+	defer this.instanceLocks.Lock(id)()
+	drift.InstanceId = id
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return drift, false
+	}
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	deployed, exists, err := this.deploymentClient.GetContainerConfig(instance.ServiceId)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	if !exists {
+		drift.Missing = true
+		return drift, !instance.Paused
+	}
+	env, err, _ := this.getEnv(&instance, "", instance.UserId, false)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	image := this.getImage(instance)
+	loaded := instance
+
+	keepDeployedConsumerGroup(&instance, deployed, env)
+
+	if deployed.Image != image {
+		drift.DesiredImage = image
+		drift.DeployedImage = deployed.Image
+		drifted = true
+	}
+	for _, key := range workerEnvKeys {
+		desiredValue, desiredOk := env[key]
+		deployedValue, deployedOk := deployed.Env[key]
+		if desiredOk != deployedOk || desiredValue != deployedValue {
+			drift.Env = append(drift.Env, model.EnvDrift{
+				Name:     key,
+				Desired:  maskEnvValue(key, desiredValue),
+				Deployed: maskEnvValue(key, deployedValue),
+			})
+			drifted = true
+		}
+	}
+	if !drifted || !redeploy {
+		return drift, drifted
+	}
+
+	log.Println("Redeploying drifted instance " + instance.Id)
+	serviceId := instance.ServiceId
+	err = this.redeploy(&instance, image, env)
+	if err != nil {
+		drift.Error = err.Error()
+		if instance.ServiceId == serviceId {
+			return drift, true
+		}
+	}
+	err = this.storeInstanceChange(&instance, "", loaded)
+	if errors.Is(err, errRevisionConflict) {
+		this.redeployStored(instance, instance.KafkaGroupId)
+	}
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	drift.Redeployed = drift.Error == ""
+	return drift, true
+}
+
+// keepDeployedConsumerGroup prevents redeployments from resetting the consumer position of records
+// stored before the consumer group was tracked in the instance: they adopt the group of their workload
+func keepDeployedConsumerGroup(instance *model.Instance, deployed model.DeployedConfig, env map[string]string) {
+	if instance.KafkaGroupId != "" {
+		return
+	}
+	if deployedGroupId := deployed.Env["KAFKA_GROUP_ID"]; strings.HasPrefix(deployedGroupId, instance.Id) {
+		instance.KafkaGroupId = deployedGroupId
+		env["KAFKA_GROUP_ID"] = deployedGroupId
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/notification"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// expiry is checked with minute precision
+const expiryInterval = time.Minute
+
+// StartExpiryLoop periodically handles expired instances according to their ExpiryAction until the controller context is done.
+// With expiry_warning and notification_url, owners are notified ahead of the expiry.
+func (this *Controller) StartExpiryLoop() error {
+	var warning time.Duration
+	if this.config.ExpiryWarning != "" {
+		var err error
+		warning, err = time.ParseDuration(this.config.ExpiryWarning)
+		if err != nil {
+			return err
+		}
+	}
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(expiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+				if warning > 0 && this.config.NotificationUrl != "" {
+					this.warnExpiringInstances(warning)
+				}
+				this.expireInstances()
+			}
+		}
+	}()
+	return nil
+}
+
+func (this *Controller) expireInstances() {
+	now := time.Now()
+	ctx, _ := util.GetTimeoutContext()
+	// deleted instances leave the list while iterating, so a single snapshot is used instead of paging
+	instances, err := this.db.ListInstances(ctx, 0, 0, "expires_at", true, "", true, nil, &now)
+	if err != nil {
+		log.Println("ERROR: unable to list expired instances:", err)
+		return
+	}
+	for _, instance := range instances {
+		if this.ctx.Err() != nil {
+			return
+		}
+		if instance.Paused && instance.ExpiryAction == model.ExpiryActionPause {
+			continue // already handled
+		}
+		err = this.expireInstance(instance.Id)
+		if err != nil {
+			log.Println("ERROR: unable to handle expired instance", instance.Id, err)
+		}
+	}
+}
+
+// expireInstance handles an expired instance according to its ExpiryAction
+func (this *Controller) expireInstance(id string) error {
+	remove, err := this.pauseExpiredInstance(id)
+	if err != nil || !remove {
+		return err
+	}
+	log.Println("Deleting expired instance " + id)
+	err, _ = this.trashInstances([]string{id}, "", nil) // locks the instance itself
+	this.audit(model.AuditActionExpire, "", id, nil, err)
+	return err
+}
+
+// pauseExpiredInstance pauses the instance if it expired with ExpiryActionPause.
+// remove reports that it expired with ExpiryActionDelete instead.
+func (this *Controller) pauseExpiredInstance(id string) (remove bool, err error) {
+	defer this.instanceLocks.Lock(id)()
+	// reload instance to see changes made while waiting for the lock
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !isExpired(instance, time.Now()) {
+		return false, nil
+	}
+	if instance.ExpiryAction != model.ExpiryActionPause {
+		return true, nil
+	}
+	if instance.Paused {
+		return false, nil
+	}
+	log.Println("Pausing expired instance " + id)
+	err = this.applyPaused(instance, "", true)
+	this.audit(model.AuditActionExpire, "", id, []model.FieldChange{{Field: "Paused", From: false, To: true}}, err)
+	return false, err
+}
+
+func (this *Controller) warnExpiringInstances(warning time.Duration) {
+	before := time.Now().Add(warning)
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "expires_at", true, "", true, nil, &before)
+		if err != nil {
+			log.Println("ERROR: unable to list expiring instances:", err)
+			return
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			if this.ctx.Err() != nil {
+				return
+			}
+			if instance.ExpiryWarnedAt != nil || isExpired(instance, time.Now()) {
+				continue
+			}
+			err = this.warnExpiry(instance.Id)
+			if err != nil {
+				log.Println("ERROR: unable to send expiry warning for", instance.Id, err)
+			}
+		}
+		if len(instances) < int(batchSize) {
+			return // done
+		}
+	}
+}
+
+func (this *Controller) warnExpiry(id string) error {
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if instance.ExpiresAt == nil || instance.ExpiryWarnedAt != nil {
+		return nil
+	}
+	loaded := instance
+	action := "deleted"
+	if instance.ExpiryAction == model.ExpiryActionPause {
+		action = "paused"
+	}
+	err = notification.Send(this.config.NotificationUrl, notification.Message{
+		UserId:  instance.UserId,
+		Title:   "Export " + instance.Name + " expires soon",
+		Message: "The export " + instance.Name + " (" + instance.Id + ") will be " + action + " at " + instance.ExpiresAt.Format(time.RFC3339) + ". Update its expiry to keep it running.",
+		Topic:   notification.Topic,
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	instance.ExpiryWarnedAt = &now
+	return this.storeInstanceChange(&instance, "", loaded)
+}
+
+func isExpired(instance model.Instance, now time.Time) bool {
+	return instance.ExpiresAt != nil && !now.Before(*instance.ExpiresAt)
+}
+
+func verifyExpiry(instance model.Instance) error {
+	switch instance.ExpiryAction {
+	case "", model.ExpiryActionDelete, model.ExpiryActionPause:
+		return nil
+	default:
+		return errors.New("unknown ExpiryAction, expected " + model.ExpiryActionDelete + " or " + model.ExpiryActionPause)
+	}
+}
+
+func equalTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
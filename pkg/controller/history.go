@@ -0,0 +1,171 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// ListInstanceRevisions returns the stored revisions of an instance, newest first. Secrets are masked.
+func (this *Controller) ListInstanceRevisions(token string, id string, limit int64, offset int64) (result []model.InstanceRevision, err error, code int) {
+	err, code = this.checkInstancePermission(token, id, permv2.Read)
+	if err != nil {
+		return nil, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	result, err = this.db.ListInstanceRevisions(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	for i := range result {
+		maskInstance(&result[i].Instance)
+	}
+	return result, nil, http.StatusOK
+}
+
+// DiffInstanceRevisions compares two revisions of an instance. A negative to compares with the current version.
+func (this *Controller) DiffInstanceRevisions(token string, id string, from int64, to int64) (result model.InstanceDiff, err error, code int) {
+	err, code = this.checkInstancePermission(token, id, permv2.Read)
+	if err != nil {
+		return result, err, code
+	}
+	fromRevision, err, code := this.getInstanceRevision(id, from)
+	if err != nil {
+		return result, err, code
+	}
+	var toInstance model.Instance
+	if to < 0 {
+		ctx, _ := util.GetTimeoutContext()
+		current, exists, err := this.db.GetInstance(ctx, id)
+		if !exists {
+			return result, errors.New("not found"), http.StatusNotFound
+		}
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		toInstance = current
+	} else {
+		toRevision, err, code := this.getInstanceRevision(id, to)
+		if err != nil {
+			return result, err, code
+		}
+		toInstance = toRevision.Instance
+	}
+	return model.InstanceDiff{
+		From:    from,
+		To:      toInstance.Revision,
+		Changes: diffFields(fromRevision.Instance, toInstance),
+	}, nil, http.StatusOK
+}
+
+// RollbackInstance updates the instance with the configuration of an earlier revision, which is stored as a new revision
+func (this *Controller) RollbackInstance(token string, userId string, id string, revision int64) (err error, code int) {
+	err, code = this.checkInstancePermission(token, id, permv2.Write)
+	if err != nil {
+		return err, code
+	}
+	target, err, code := this.getInstanceRevision(id, revision)
+	if err != nil {
+		return err, code
+	}
+	return this.setInstance(target.Instance, userId, token, model.AuditActionRollback, nil)
+}
+
+func (this *Controller) checkInstancePermission(token string, id string, permission permv2.Permission) (err error, code int) {
+	ok, err, code := this.permv2.CheckPermission(token, Permv2topic, id, permission)
+	if err != nil {
+		return err, code
+	}
+	if !ok {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	return nil, http.StatusOK
+}
+
+func (this *Controller) getInstanceRevision(id string, revision int64) (result model.InstanceRevision, err error, code int) {
+	ctx, _ := util.GetTimeoutContext()
+	result, exists, err := this.db.GetInstanceRevision(ctx, id, revision)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if !exists {
+		return result, errors.New("revision not found"), http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+// storeInstance writes the instance together with its revision. Instances stored before revisions were introduced
+// have revision 0, previous is stored as that revision so that the first change can be rolled back.
+// Updates fail with errRevisionConflict if the stored instance is no longer at the revision of previous.
+func (this *Controller) storeInstance(instance model.Instance, userId string, previous *model.Instance) (err error) {
+	ctx, _ := getTimeoutContext()
+	ctx, finish, err := this.db.Transaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		finishErr := finish(err == nil)
+		if err == nil {
+			err = finishErr
+		}
+		if err == nil {
+			this.notifyLifecyclePublisher()
+		}
+	}()
+	return this.writeInstance(ctx, instance, userId, previous)
+}
+
+// writeInstance is storeInstance within the transaction of ctx, the lifecycle event of the change is added to the outbox
+func (this *Controller) writeInstance(ctx context.Context, instance model.Instance, userId string, previous *model.Instance) (err error) {
+	if previous != nil && previous.Revision == 0 {
+		err = this.db.AddInstanceRevision(ctx, model.InstanceRevision{
+			InstanceId: previous.Id,
+			Revision:   0,
+			Timestamp:  previous.UpdatedAt,
+			Instance:   *previous,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if previous == nil {
+		err = this.db.SetInstance(ctx, instance)
+	} else {
+		err = this.replaceInstance(ctx, instance, previous.Revision)
+	}
+	if err != nil {
+		return err
+	}
+	err = this.db.AddInstanceRevision(ctx, model.InstanceRevision{
+		InstanceId: instance.Id,
+		Revision:   instance.Revision,
+		UserId:     userId,
+		Timestamp:  time.Now(),
+		Instance:   instance,
+	})
+	if err != nil {
+		return err
+	}
+	return this.addLifecycleEvent(ctx, userId, previous, &instance)
+}
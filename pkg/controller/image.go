@@ -0,0 +1,59 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+// getImage returns the configured transfer image or, if the instance pins a version,
+// the repository of the configured transfer image with the pinned tag or digest
+func (this *Controller) getImage(instance model.Instance) string {
+	if instance.ImageVersion == nil || *instance.ImageVersion == "" {
+		return this.config.TransferImage
+	}
+	repository := imageRepository(this.config.TransferImage)
+	if strings.Contains(*instance.ImageVersion, ":") {
+		return repository + "@" + *instance.ImageVersion // digest, e.g. sha256:...
+	}
+	return repository + ":" + *instance.ImageVersion
+}
+
+func (this *Controller) verifyImageVersion(instance model.Instance) (err error, code int) {
+	if instance.ImageVersion == nil || *instance.ImageVersion == "" {
+		return nil, http.StatusOK
+	}
+	if !slices.Contains(this.config.TransferImageVersions, *instance.ImageVersion) {
+		return errors.New("image version not allowed"), http.StatusBadRequest
+	}
+	return nil, http.StatusOK
+}
+
+// imageRepository removes tag and digest from an image reference, respecting registry ports (e.g. localhost:5000/image:tag)
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	lastColon := strings.LastIndex(image, ":")
+	if lastColon > strings.LastIndex(image, "/") {
+		image = image[:lastColon]
+	}
+	return image
+}
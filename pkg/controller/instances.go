@@ -19,14 +19,17 @@ package controller
 import (
 	"errors"
 	"fmt"
+	"io"
 
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/deploy"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
-	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
 	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
 
 	"log"
+	"maps"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -39,17 +42,22 @@ const filterDevice = "deviceId"
 const filterImport = "import_id"
 const filterOperator = "operatorId"
 
-func (this *Controller) ListInstances(token string, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool) (results []model.Instance, total int, err error, errCode int) {
+func (this *Controller) ListInstances(token string, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, expiresBefore *time.Time) (results []model.Instance, total int, err error, errCode int) {
 	ids, err, errCode := this.permv2.ListAccessibleResourceIds(token, Permv2topic, permv2.ListOptions{}, permv2.Read)
 	if err != nil {
 		return nil, 0, err, errCode
 	}
 	ctx, _ := getTimeoutContext()
-	results, err = this.db.ListInstances(ctx, limit, offset, sort, asc, search, includeGenerated, ids)
+	results, err = this.db.ListInstances(ctx, limit, offset, sort, asc, search, includeGenerated, ids, expiresBefore)
 	if err != nil {
 		return results, 0, err, http.StatusInternalServerError
 	}
-	return results, len(ids), nil, http.StatusOK
+	count, err := this.db.CountListedInstances(ctx, search, includeGenerated, ids, expiresBefore)
+	if err != nil {
+		return results, 0, err, http.StatusInternalServerError
+	}
+	this.addDeploymentStatus(results)
+	return results, int(count), nil, http.StatusOK
 }
 
 func (this *Controller) ReadInstance(token string, id string) (result model.Instance, err error, errCode int) {
@@ -68,40 +76,91 @@ func (this *Controller) ReadInstance(token string, id string) (result model.Inst
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	result.Status = this.getDeploymentStatus(result)
 	return result, nil, http.StatusOK
 }
 
+func (this *Controller) GetInstanceLogs(token string, id string, options model.LogOptions) (logs io.ReadCloser, err error, errCode int) {
+	ok, err, errCode := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Read)
+	if err != nil {
+		return nil, err, errCode
+	}
+	if !ok {
+		return nil, errors.New("not found"), http.StatusNotFound
+	}
+	ctx, _ := getTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return nil, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	logs, err = this.deploymentClient.GetContainerLogs(instance.ServiceId, options)
+	if errors.Is(err, deploy.ErrNotSupported) {
+		return nil, err, http.StatusNotImplemented
+	}
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return logs, nil, http.StatusOK
+}
+
 func (this *Controller) CreateInstance(instance model.Instance, userId string, token string) (result model.Instance, err error, code int) {
-	if instance.Id != "" {
-		return result, errors.New("explicit setting of id not allowed"), http.StatusBadRequest
+	instance.TemplateId = "" // linking requires read permission on the template, see CreateInstanceFromTemplate
+	return this.createInstance(instance, userId, token, tokenRoles(token, userId), model.AuditActionCreate, "")
+}
+
+// createInstance deploys and stores a new instance, the call is recorded in the audit log as action.
+// The instance gets the given id, a new one is generated if it is empty. roles are the roles of the caller for the quota check.
+func (this *Controller) createInstance(instance model.Instance, userId string, token string, roles []string, action string, id string) (result model.Instance, err error, code int) {
+	if id == "" {
+		// generated up front, so failed creations are audited with the id they would have had
+		id, err = newInstanceId()
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
 	}
-	id, err := uuid.GenerateUUID()
+	defer func() {
+		this.audit(action, userId, id, diffFields(model.Instance{}, result), err)
+	}()
+	instance, env, err, code := this.prepareCreate(instance, userId, token, id)
+	if err != nil {
+		log.Println("Cant prepare instance: " + err.Error())
+		return result, err, code
+	}
+	unlockQuota, err := this.lockQuota(instance.UserId)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
-	instance.Id = idPrefix + id
-	instance.UserId = userId
-
-	env, err, code := this.getEnv(&instance, token, userId, true)
+	defer unlockQuota()
+	err, code = this.checkQuota(roles, instance.UserId, 1)
 	if err != nil {
-		log.Println("Cant get env: " + err.Error())
 		return result, err, code
 	}
 
-	instance.ServiceId, err = this.deploymentClient.CreateContainer(containerNamePrefix+strings.TrimPrefix(instance.Id, idPrefix), this.config.TransferImage, instance.UserId, env, true)
+	instance.ServiceId, err = this.deploymentClient.CreateContainer(containerName(instance), this.getImage(instance), instance.UserId, env, true)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	if !shouldRun(instance) {
+		err = this.deploymentClient.StopContainer(instance.ServiceId)
+		if err != nil {
+			this.compensate(model.Compensation{Action: model.CompensationRemoveInstance, InstanceId: instance.Id, ServiceId: instance.ServiceId})
+			return result, err, http.StatusInternalServerError
+		}
+	}
 
 	now := time.Now()
 	instance.CreatedAt = now
 	instance.UpdatedAt = now
-	ctx, _ := getTimeoutContext()
-	err = this.db.SetInstance(ctx, instance)
+	instance.Revision = 1
+	err = this.storeInstance(instance, userId, nil)
 	if err != nil {
+		this.compensate(model.Compensation{Action: model.CompensationRemoveInstance, InstanceId: instance.Id, ServiceId: instance.ServiceId})
 		return result, err, http.StatusInternalServerError
 	}
-	this.permv2.SetPermission(token, Permv2topic, id, permv2.ResourcePermissions{
+	_, err, code = this.permv2.SetPermission(token, Permv2topic, instance.Id, permv2.ResourcePermissions{
 		UserPermissions: map[string]permv2.PermissionsMap{
 			instance.UserId: {
 				Read:         true,
@@ -119,10 +178,50 @@ func (this *Controller) CreateInstance(instance model.Instance, userId string, t
 			},
 		},
 	})
+	if err != nil {
+		this.compensate(model.Compensation{Action: model.CompensationRemoveInstance, InstanceId: instance.Id, ServiceId: instance.ServiceId})
+		if code < http.StatusBadRequest {
+			code = http.StatusInternalServerError
+		}
+		return result, err, code
+	}
 	return instance, nil, http.StatusOK
 }
 
-func (this *Controller) SetInstance(instance model.Instance, userId string, token string) (err error, code int) {
+// prepareCreate assigns the id, or a new one if it is empty, and renders the worker configuration without touching the deployment backend or the database.
+// All validation errors are joined into err, code belongs to the first one.
+func (this *Controller) prepareCreate(instance model.Instance, userId string, token string, id string) (result model.Instance, env map[string]string, err error, code int) {
+	if instance.Id != "" {
+		return result, nil, errors.New("explicit setting of id not allowed"), http.StatusBadRequest
+	}
+	if id == "" {
+		id, err = newInstanceId()
+		if err != nil {
+			return result, nil, err, http.StatusInternalServerError
+		}
+	}
+	instance.Id = id
+	instance.UserId = userId
+	instance.Paused = false
+	instance.DeletedAt = nil
+	instance.KafkaGroupId = ""
+	instance.ExpiryWarnedAt = nil
+
+	env, err, code = this.verifyAndRenderEnv(&instance, token, userId)
+	return instance, env, err, code
+}
+
+// SetInstance updates the instance. With ifMatch, it is only updated if it still has one of these revisions.
+func (this *Controller) SetInstance(instance model.Instance, userId string, token string, ifMatch []int64) (err error, code int) {
+	return this.setInstance(instance, userId, token, model.AuditActionUpdate, ifMatch)
+}
+
+// setInstance updates and redeploys an instance, the call is recorded in the audit log as action
+func (this *Controller) setInstance(instance model.Instance, userId string, token string, action string, ifMatch []int64) (err error, code int) {
+	var changes []model.FieldChange
+	defer func() {
+		this.audit(action, userId, instance.Id, changes, err)
+	}()
 	ok, err, errCode := this.permv2.CheckPermission(token, Permv2topic, instance.Id, permv2.Write)
 	if err != nil {
 		return err, errCode
@@ -130,42 +229,190 @@ func (this *Controller) SetInstance(instance model.Instance, userId string, toke
 	if !ok {
 		return fmt.Errorf("not found"), http.StatusNotFound
 	}
+	defer this.instanceLocks.Lock(instance.Id)()
+	_, changes, err, code = this.updateInstance(instance, userId, token, ifMatch)
+	return err, code
+}
+
+// updateInstance stores the update and redeploys the instance if its worker configuration or image changed.
+// With expectedRevisions, the update is rejected if the stored instance has none of these revisions.
+// The caller is responsible for permission checks and has to hold the lock of the instance.
+func (this *Controller) updateInstance(instance model.Instance, userId string, token string, expectedRevisions []int64) (result model.Instance, changes []model.FieldChange, err error, code int) {
+	existing, prepared, env, err, code := this.prepareUpdate(instance, userId, token)
+	if err != nil {
+		return result, nil, err, code
+	}
+	if expectedRevisions != nil && !slices.Contains(expectedRevisions, existing.Revision) {
+		return result, nil, errRevisionConflict, http.StatusPreconditionFailed
+	}
+	instance = prepared
+	changes = diffFields(existing, instance)
+
+	if !this.affectsWorkload(existing, instance, env) {
+		// metadata only, e.g. Name or Description: the workload is kept and only started or stopped if the schedule changed
+		err = this.applyRunState(existing, instance)
+		if err != nil {
+			return result, changes, err, http.StatusInternalServerError
+		}
+		instance.UpdatedAt = time.Now()
+		instance.Revision = existing.Revision + 1
+		err = this.storeInstance(instance, userId, &existing)
+		if err != nil {
+			revertErr := this.applyRunState(instance, existing)
+			if revertErr != nil {
+				log.Println("ERROR: unable to revert run state of", instance.Id, revertErr)
+			}
+			return result, changes, err, storeErrorCode(err)
+		}
+		return instance, changes, nil, http.StatusOK
+	}
+
+	// everything needed to roll back has to be known before the workload is replaced
+	previousImage, previousGroupId := this.deployedVersion(existing)
+	if instance.KafkaGroupId == "" && previousGroupId != "" {
+		// records stored before the consumer group was tracked adopt the group of their workload,
+		// which keeps the position of replays and earlier offset changes
+		instance.KafkaGroupId = previousGroupId
+		env["KAFKA_GROUP_ID"] = previousGroupId
+	}
+	instance.ServiceId = existing.ServiceId
+	err = this.redeploy(&instance, this.getImage(instance), env)
+	if err != nil {
+		this.rollbackUpdate(existing, instance.ServiceId, previousImage, previousGroupId)
+		return result, changes, err, http.StatusInternalServerError
+	}
+	instance.UpdatedAt = time.Now()
+	instance.Revision = existing.Revision + 1
+	err = this.storeInstance(instance, userId, &existing)
+	if errors.Is(err, errRevisionConflict) {
+		this.redeployStored(instance, previousGroupId)
+		return result, changes, err, http.StatusPreconditionFailed
+	}
+	if err != nil {
+		this.rollbackUpdate(existing, instance.ServiceId, previousImage, previousGroupId)
+		return result, changes, err, http.StatusInternalServerError
+	}
+	return instance, changes, nil, http.StatusOK
+}
+
+// affectsWorkload reports if the update changes the rendered worker configuration or the image of the instance
+func (this *Controller) affectsWorkload(existing model.Instance, updated model.Instance, env map[string]string) bool {
+	if this.getImage(existing) != this.getImage(updated) {
+		return true
+	}
+	existingEnv, err, _ := this.getEnv(&existing, "", existing.UserId, false)
+	if err != nil {
+		return true
+	}
+	return !maps.Equal(existingEnv, env)
+}
+
+// applyRunState starts or stops the workload if it is expected to run in only one of the given versions of the instance
+func (this *Controller) applyRunState(from model.Instance, to model.Instance) error {
+	switch {
+	case shouldRun(from) == shouldRun(to):
+		return nil
+	case shouldRun(to):
+		return this.deploymentClient.StartContainer(to.ServiceId)
+	default:
+		return this.deploymentClient.StopContainer(to.ServiceId)
+	}
+}
+
+// prepareUpdate loads the stored instance and renders the worker configuration of the update without touching the deployment backend or the database.
+// The caller is responsible for permission checks. All validation errors are joined into err, code belongs to the first one.
+func (this *Controller) prepareUpdate(instance model.Instance, userId string, token string) (existing model.Instance, result model.Instance, env map[string]string, err error, code int) {
 	ctx, _ := getTimeoutContext()
 	existing, exists, err := this.db.GetInstance(ctx, instance.Id)
 	if !exists {
-		return errors.New("not found"), http.StatusNotFound
+		return existing, result, nil, errors.New("not found"), http.StatusNotFound
 	}
 	if err != nil {
-		return err, http.StatusInternalServerError
+		return existing, result, nil, err, http.StatusInternalServerError
 	}
 	instance.UserId = existing.UserId
+	instance.ServiceId = existing.ServiceId
+	instance.Paused = existing.Paused
+	instance.CreatedAt = existing.CreatedAt
+	instance.TemplateId = existing.TemplateId
+	instance.DeletedAt = nil
+	instance.KafkaGroupId = existing.KafkaGroupId
+	instance.ExpiryWarnedAt = nil
+	if equalTime(existing.ExpiresAt, instance.ExpiresAt) {
+		instance.ExpiryWarnedAt = existing.ExpiryWarnedAt
+	}
 
-	env, err, code := this.getEnv(&instance, token, userId, true)
+	env, err, code = this.verifyAndRenderEnv(&instance, token, userId)
 	if err != nil {
-		return err, code
+		return existing, instance, env, err, code
 	}
 
 	if (existing.Offset != instance.Offset) || (existing.Offset == "smallest" && !reflect.DeepEqual(existing.Values, instance.Values)) {
-		err = refreshConsumerGroupId(instance, env)
+		err = refreshConsumerGroupId(&instance, env)
 		if err != nil {
-			return err, http.StatusInternalServerError
+			return existing, instance, env, err, http.StatusInternalServerError
 		}
 	}
+	return existing, instance, env, nil, http.StatusOK
+}
 
-	instance.ServiceId, err = this.deploymentClient.UpdateContainer(existing.ServiceId, containerNamePrefix+strings.TrimPrefix(instance.Id, idPrefix), this.config.TransferImage, instance.UserId, env, true)
-	if err != nil {
-		return err, http.StatusInternalServerError
+// verifyAndRenderEnv collects all validation errors of the instance instead of stopping at the first one.
+// Internal errors (code >= 500) are returned immediately.
+func (this *Controller) verifyAndRenderEnv(instance *model.Instance, token string, userId string) (env map[string]string, err error, code int) {
+	validationErrors := []error{}
+	code = http.StatusOK
+	collect := func(e error, c int) {
+		validationErrors = append(validationErrors, e)
+		if code == http.StatusOK {
+			code = c
+		}
 	}
-	instance.UpdatedAt = time.Now()
-	ctx, _ = getTimeoutContext()
-	err = this.db.SetInstance(ctx, instance)
-	if err != nil {
-		return err, http.StatusInternalServerError
+	imageErr, imageCode := this.verifyImageVersion(*instance)
+	if imageErr != nil {
+		collect(imageErr, imageCode)
+	}
+	expiryErr := verifyExpiry(*instance)
+	if expiryErr != nil {
+		collect(expiryErr, http.StatusBadRequest)
+	}
+	scheduleErr := verifySchedule(instance.Schedule)
+	if scheduleErr != nil {
+		collect(scheduleErr, http.StatusBadRequest)
+	} else {
+		instance.ScheduleState = scheduleState(instance.Schedule, time.Now())
+	}
+	env, envErr, envCode := this.getEnv(instance, token, userId, true)
+	if envErr != nil {
+		if envCode >= http.StatusInternalServerError {
+			return nil, envErr, envCode
+		}
+		collect(envErr, envCode)
+	}
+	if len(validationErrors) > 0 {
+		return env, errors.Join(validationErrors...), code
 	}
-	return nil, http.StatusOK
+	return env, nil, http.StatusOK
+}
+
+func (this *Controller) DeleteInstances(token string, userId string, ids []string) (err error, errCode int) {
+	return this.deleteInstances(token, userId, ids, nil)
 }
 
-func (this *Controller) DeleteInstances(token string, ids []string) (err error, errCode int) {
+// DeleteInstance moves the instance to the trash. With ifMatch, it is only deleted if it still has one of these revisions.
+func (this *Controller) DeleteInstance(token string, userId string, id string, ifMatch []int64) (err error, errCode int) {
+	var expectedRevisions map[string][]int64
+	if ifMatch != nil {
+		expectedRevisions = map[string][]int64{id: ifMatch}
+	}
+	return this.deleteInstances(token, userId, []string{id}, expectedRevisions)
+}
+
+func (this *Controller) deleteInstances(token string, userId string, ids []string, expectedRevisions map[string][]int64) (err error, errCode int) {
+	defer func() {
+		for _, id := range ids {
+			this.audit(model.AuditActionDelete, userId, id, nil, err)
+		}
+	}()
 	access, err, errCode := this.permv2.CheckMultiplePermissions(token, Permv2topic, ids, permv2.Administrate)
 	if err != nil {
 		return err, errCode
@@ -175,75 +422,195 @@ func (this *Controller) DeleteInstances(token string, ids []string) (err error,
 			return errors.New("not found"), http.StatusNotFound
 		}
 	}
+	return this.trashInstances(ids, userId, expectedRevisions)
+}
+
+// purgeInstances permanently removes all given instances or none of them. Active workloads are stopped first and restarted if the records can not be removed.
+// Removing workloads and permissions is recorded within the same transaction as the records, so it is retried if it fails afterwards.
+// With trashed, the instances are expected to be in the trash, otherwise they have to be active.
+func (this *Controller) purgeInstances(ids []string, userId string, trashed bool) (err error, errCode int) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids) // consistent lock order
+	ids = slices.Compact(ids)
+	for _, id := range ids {
+		defer this.instanceLocks.Lock(id)()
+	}
 	ctx, _ := getTimeoutContext()
-	instances, exists, err := this.db.GetInstances(ctx, ids)
+	var instances []model.Instance
+	var exists bool
+	if trashed {
+		instances, exists, err = this.db.GetDeletedInstances(ctx, ids)
+	} else {
+		instances, exists, err = this.db.GetInstances(ctx, ids)
+	}
 	if !exists {
 		return errors.New("not found"), http.StatusNotFound
 	}
 	if err != nil {
 		return err, http.StatusInternalServerError
 	}
-	for i := range instances {
-		err = this.deploymentClient.RemoveContainer(instances[i].ServiceId)
+
+	stopped, err := this.stopWorkloads(instances)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+
+	cleanups := []model.Compensation{}
+	for _, instance := range instances {
+		cleanup := model.Compensation{Action: model.CompensationCleanupDeleted, InstanceId: instance.Id, ServiceId: instance.ServiceId}
+		err = this.newCompensation(&cleanup)
 		if err != nil {
+			this.restartWorkloads(stopped)
 			return err, http.StatusInternalServerError
 		}
-		ctx, _ := getTimeoutContext()
-		err = this.db.RemoveInstances(ctx, []string{instances[i].Id})
+		cleanups = append(cleanups, cleanup)
+	}
+	err = this.removeInstanceRecords(instances, userId, cleanups)
+	if err != nil {
+		this.restartWorkloads(stopped)
+		return err, http.StatusInternalServerError
+	}
+
+	for _, cleanup := range cleanups {
+		err = this.retryCompensation(cleanup)
 		if err != nil {
-			return err, http.StatusInternalServerError
+			log.Println("ERROR: unable to clean up deleted instance", cleanup.InstanceId+", will retry:", err)
 		}
-		this.permv2.RemoveResource(token, Permv2topic, instances[i].Id)
 	}
-
 	return nil, http.StatusNoContent
 }
 
-func (this *Controller) EnsureAllInstancesDeployed() (err error) {
-	var offset int64 = 0
-	var batchSize int64 = 100
-	for {
-		ctx, _ := util.GetTimeoutContext()
-		instances, err := this.db.ListInstances(ctx, batchSize, offset, "name", true, "", true, nil)
-		if err != nil {
-			return err
+// stopWorkloads stops the workloads of all running instances. If one fails, the already stopped ones are restarted.
+func (this *Controller) stopWorkloads(instances []model.Instance) (stopped []model.Instance, err error) {
+	for _, instance := range instances {
+		if !shouldRun(instance) || instance.DeletedAt != nil {
+			continue
 		}
-		offset += int64(len(instances))
-		for _, instance := range instances {
-			exists, err := this.deploymentClient.ContainerExists(instance.ServiceId)
-			if err != nil {
-				return err
-			}
-			if exists {
-				log.Println(instance.Id + " still exists")
-				continue
-			}
-			log.Println("Recreating " + instance.Id)
-			env, err, _ := this.getEnv(&instance, "", instance.UserId, false)
-			if err != nil {
-				return err
-			}
-			instance.ServiceId, err = this.deploymentClient.CreateContainer(containerNamePrefix+strings.TrimPrefix(instance.Id, idPrefix), this.config.TransferImage, instance.UserId, env, true)
-			if err != nil {
-				return err
-			}
-			ctx, _ := util.GetTimeoutContext()
-			err = this.db.SetInstance(ctx, instance)
-			if err != nil {
-				return err
+		err = this.deploymentClient.StopContainer(instance.ServiceId)
+		if err != nil {
+			if exists, existsErr := this.deploymentClient.ContainerExists(instance.ServiceId); existsErr == nil && !exists {
+				continue // nothing to stop, the workload is already gone
 			}
+			this.restartWorkloads(stopped)
+			return nil, err
 		}
-		if len(instances) < int(batchSize) {
-			return nil // done
+		stopped = append(stopped, instance)
+	}
+	return stopped, nil
+}
+
+func (this *Controller) restartWorkloads(stopped []model.Instance) {
+	for _, instance := range stopped {
+		startErr := this.deploymentClient.StartContainer(instance.ServiceId)
+		if startErr != nil {
+			log.Println("ERROR: unable to restart", instance.Id, "after failed delete:", startErr)
 		}
 	}
 }
 
+func (this *Controller) removeInstanceRecords(instances []model.Instance, userId string, cleanups []model.Compensation) (err error) {
+	ctx, _ := getTimeoutContext()
+	ctx, finish, err := this.db.Transaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		finishErr := finish(err == nil)
+		if err == nil {
+			err = finishErr
+		}
+		if err == nil {
+			this.notifyLifecyclePublisher()
+		}
+	}()
+	for _, cleanup := range cleanups {
+		err = this.db.SetCompensation(ctx, cleanup)
+		if err != nil {
+			return err
+		}
+	}
+	ids := []string{}
+	for i := range instances {
+		ids = append(ids, instances[i].Id)
+		err = this.addLifecycleEvent(ctx, userId, &instances[i], nil)
+		if err != nil {
+			return err
+		}
+	}
+	err = this.db.RemoveInstanceRevisions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	return this.db.RemoveInstances(ctx, ids)
+}
+
+func (this *Controller) PauseInstance(token string, userId string, id string) (err error, errCode int) {
+	defer func() {
+		this.audit(model.AuditActionPause, userId, id, nil, err)
+	}()
+	return this.setPaused(token, userId, id, true)
+}
+
+func (this *Controller) ResumeInstance(token string, userId string, id string) (err error, errCode int) {
+	defer func() {
+		this.audit(model.AuditActionResume, userId, id, nil, err)
+	}()
+	return this.setPaused(token, userId, id, false)
+}
+
+func (this *Controller) setPaused(token string, userId string, id string, paused bool) (err error, errCode int) {
+	ok, err, errCode := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Execute)
+	if err != nil {
+		return err, errCode
+	}
+	if !ok {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := getTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if instance.Paused == paused {
+		return nil, http.StatusNoContent
+	}
+	if !paused && isExpired(instance, time.Now()) {
+		return errors.New("instance expired, extend or remove ExpiresAt to resume it"), http.StatusConflict
+	}
+	err = this.applyPaused(instance, userId, paused)
+	if err != nil {
+		return err, storeErrorCode(err)
+	}
+	return nil, http.StatusNoContent
+}
+
+// applyPaused starts or stops the workload and stores the paused state. The caller has to hold the lock of the instance.
+func (this *Controller) applyPaused(instance model.Instance, userId string, paused bool) (err error) {
+	loaded := instance
+	instance.Paused = paused
+	if paused {
+		err = this.deploymentClient.StopContainer(instance.ServiceId)
+	} else if shouldRun(instance) {
+		err = this.deploymentClient.StartContainer(instance.ServiceId)
+	}
+	if err != nil {
+		return err
+	}
+	return this.storeRunState(instance, userId, loaded)
+}
+
 func (this *Controller) getEnv(instance *model.Instance, token string, userId string, verify bool) (m map[string]string, err error, code int) {
 	m = map[string]string{}
 	m["KAFKA_BOOTSTRAP"] = this.config.KafkaBootstrap
 	m["KAFKA_TOPIC"] = instance.Topic
 	m["KAFKA_GROUP_ID"] = instance.Id
+	if instance.KafkaGroupId != "" {
+		m["KAFKA_GROUP_ID"] = instance.KafkaGroupId
+	}
 	m["KAFKA_OFFSET"] = instance.Offset
 	m["FILTER_QUERY"] = "."
 	switch instance.FilterType {
@@ -327,11 +694,38 @@ func (this *Controller) getEnv(instance *model.Instance, token string, userId st
 	return m, nil, http.StatusOK
 }
 
-func refreshConsumerGroupId(instance model.Instance, env map[string]string) error {
+func containerName(instance model.Instance) string {
+	return containerNamePrefix + strings.TrimPrefix(instance.Id, idPrefix)
+}
+
+// redeploy replaces the workload of the instance and keeps paused or unscheduled instances stopped
+func (this *Controller) redeploy(instance *model.Instance, image string, env map[string]string) (err error) {
+	serviceId, err := this.deploymentClient.UpdateContainer(instance.ServiceId, containerName(*instance), image, instance.UserId, env, true)
+	if err != nil {
+		return err
+	}
+	instance.ServiceId = serviceId
+	if !shouldRun(*instance) {
+		return this.deploymentClient.StopContainer(instance.ServiceId)
+	}
+	return nil
+}
+
+func newInstanceId() (string, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+	return idPrefix + id, nil
+}
+
+// refreshConsumerGroupId switches the instance to a new consumer group, which starts at the configured Offset
+func refreshConsumerGroupId(instance *model.Instance, env map[string]string) error {
 	id, err := uuid.GenerateUUID()
 	if err != nil {
 		return err
 	}
-	env["KAFKA_GROUP_ID"] = instance.Id + "_" + id
+	instance.KafkaGroupId = instance.Id + "_" + id
+	env["KAFKA_GROUP_ID"] = instance.KafkaGroupId
 	return nil
 }
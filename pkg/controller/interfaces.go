@@ -18,6 +18,9 @@ package controller
 
 import (
 	"context"
+	"io"
+	"time"
+
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 )
 
@@ -27,11 +30,68 @@ type Driver interface {
 }
 
 type Database interface {
-	ListInstances(ctx context.Context, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, ids []string) (result []model.Instance, err error)
+	ListInstances(ctx context.Context, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, ids []string, expiresBefore *time.Time) (result []model.Instance, err error)
+	CountListedInstances(ctx context.Context, search string, includeGenerated bool, ids []string, expiresBefore *time.Time) (count int64, err error)
 	GetInstance(ctx context.Context, id string) (instance model.Instance, exists bool, err error)
 	SetInstance(ctx context.Context, instance model.Instance) error
+	ReplaceInstance(ctx context.Context, instance model.Instance, revision int64) (matched bool, err error)
 	GetInstances(ctx context.Context, ids []string) (result []model.Instance, allExist bool, err error)
+	GetDeletedInstances(ctx context.Context, ids []string) (result []model.Instance, allExist bool, err error)
+	ListDeletedInstances(ctx context.Context, limit int64, offset int64, ids []string, deletedBefore *time.Time) (result []model.Instance, err error)
 	RemoveInstances(ctx context.Context, ids []string) error
+	CountInstances(ctx context.Context, userId string) (count int64, err error)
+	AddInstanceRevision(ctx context.Context, revision model.InstanceRevision) error
+	ListInstanceRevisions(ctx context.Context, instanceId string, limit int64, offset int64) (result []model.InstanceRevision, err error)
+	GetInstanceRevision(ctx context.Context, instanceId string, revision int64) (result model.InstanceRevision, exists bool, err error)
+	RemoveInstanceRevisions(ctx context.Context, instanceIds []string) error
+	Transaction(ctx context.Context) (resultCtx context.Context, close func(success bool) error, err error)
+
+	ListCompensations(ctx context.Context, limit int64, offset int64) (result []model.Compensation, err error)
+	SetCompensation(ctx context.Context, compensation model.Compensation) error
+	RemoveCompensation(ctx context.Context, id string) error
+
+	ListTemplates(ctx context.Context, limit int64, offset int64, ids []string) (result []model.Template, err error)
+	GetTemplate(ctx context.Context, id string) (template model.Template, exists bool, err error)
+	SetTemplate(ctx context.Context, template model.Template) error
+	RemoveTemplate(ctx context.Context, id string) error
+	ListInstanceIdsByTemplate(ctx context.Context, templateId string) (ids []string, err error)
+
+	AddAuditEvent(ctx context.Context, event model.AuditEvent) error
+	ListAuditEvents(ctx context.Context, query model.AuditQuery) (result []model.AuditEvent, err error)
+
+	ListQuotas(ctx context.Context) (result []model.Quota, err error)
+	GetQuotas(ctx context.Context, userId string, roles []string) (result []model.Quota, err error)
+	SetQuota(ctx context.Context, quota model.Quota) error
+	RemoveQuota(ctx context.Context, quotaType string, subject string) (exists bool, err error)
+	AcquireLock(ctx context.Context, key string, owner string, lockedUntil time.Time) (acquired bool, err error)
+	ExtendLock(ctx context.Context, key string, owner string, lockedUntil time.Time) (locked bool, err error)
+	ReleaseLock(ctx context.Context, key string, owner string) error
+
+	GetUpgradeProgress(ctx context.Context) (progress model.UpgradeProgress, exists bool, err error)
+	SetUpgradeProgress(ctx context.Context, progress model.UpgradeProgress) error
+	UpdateUpgradeProgress(ctx context.Context, progress model.UpgradeProgress) error
+	RequestUpgradeCancel(ctx context.Context) (running bool, err error)
+
+	AddOperation(ctx context.Context, operation model.Operation) error
+	GetOperation(ctx context.Context, id string) (operation model.Operation, exists bool, err error)
+	ClaimOperation(ctx context.Context, owner string, lockedUntil time.Time) (operation model.Operation, found bool, err error)
+	ExtendOperationLock(ctx context.Context, id string, owner string, lockedUntil time.Time) (locked bool, err error)
+	FinishOperation(ctx context.Context, operation model.Operation, owner string) (locked bool, err error)
+	RemoveFinishedOperations(ctx context.Context, finishedBefore time.Time) error
+	AddOutboxEvent(ctx context.Context, event model.LifecycleEvent) error
+	ListOutboxEvents(ctx context.Context, limit int64) (result []model.LifecycleEvent, err error)
+	RemoveOutboxEvents(ctx context.Context, ids []string) error
+	ListWebhooks(ctx context.Context, userId string) (result []model.Webhook, err error)
+	GetWebhook(ctx context.Context, id string) (webhook model.Webhook, exists bool, err error)
+	AddWebhook(ctx context.Context, webhook model.Webhook) error
+	RemoveWebhook(ctx context.Context, id string) (exists bool, err error)
+	AddWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	SetWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	ClaimWebhookDelivery(ctx context.Context, now time.Time, lockedUntil time.Time) (delivery model.WebhookDelivery, found bool, err error)
+	ListWebhookDeliveries(ctx context.Context, webhookId string, limit int64, offset int64) (result []model.WebhookDelivery, err error)
+	RemoveFinishedWebhookDeliveries(ctx context.Context, finishedBefore time.Time) error
+	GetInstanceStates(ctx context.Context, ids []string) (result []model.InstanceState, err error)
+	SetInstanceState(ctx context.Context, state model.InstanceState, previous string) (changed bool, err error)
 }
 
 type DeploymentClient interface {
@@ -39,6 +99,20 @@ type DeploymentClient interface {
 	UpdateContainer(id string, name string, image string, userid string, env map[string]string, restart bool) (newId string, err error)
 	RemoveContainer(id string) (err error)
 	ContainerExists(id string) (exists bool, err error)
+	StopContainer(id string) (err error)
+	StartContainer(id string) (err error)
+	GetContainerStatus(id string) (status model.DeploymentStatus, err error)
+	GetContainerLogs(id string, options model.LogOptions) (logs io.ReadCloser, err error)
+	GetContainerConfig(id string) (deployed model.DeployedConfig, exists bool, err error)
+	ListContainers(namePrefix string) (workloads []model.Workload, err error)
+}
+
+type KafkaClient interface {
+	GetOffsetRanges(topic string) (first map[int]int64, end map[int]int64, err error)
+	GetOffsetsForTime(topic string, at time.Time) (offsets map[int]int64, err error)
+	GetCommittedOffsets(groupId string, topic string) (offsets map[int]int64, err error)
+	CommitOffsets(groupId string, topic string, offsets map[int]int64) error
+	PublishLifecycleEvents(topic string, events []model.LifecycleEvent) error
 }
 
 type KafkaAdmin interface {
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	"github.com/hashicorp/go-uuid"
+)
+
+// the outbox is polled this often, events added by this replica are published immediately
+const lifecyclePublishInterval = 5 * time.Second
+
+// events published per kafka request
+const lifecycleBatchSize = 100
+
+// failures of these actions are published as failed events, the others fail without touching the workload
+var lifecycleFailureActions = []string{
+	model.AuditActionCreate,
+	model.AuditActionClone,
+	model.AuditActionUpdate,
+	model.AuditActionRollback,
+	model.AuditActionReplay,
+	model.AuditActionResume,
+	model.AuditActionExpire,
+	model.AuditActionRestore,
+}
+
+// StartLifecyclePublisher publishes the lifecycle events of the outbox to the lifecycle_topic until the controller context is done.
+// Events stay in the outbox while kafka is unavailable. The publisher is disabled if lifecycle_topic is empty.
+func (this *Controller) StartLifecyclePublisher() {
+	if this.config.LifecycleTopic == "" {
+		return
+	}
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(lifecyclePublishInterval)
+		defer ticker.Stop()
+		for {
+			err := this.publishOutbox()
+			if err != nil {
+				log.Println("ERROR: unable to publish lifecycle events, will retry:", err)
+			}
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-this.lifecycleQueued:
+			}
+		}
+	}()
+}
+
+// publishOutbox publishes and removes the events of the outbox until it is empty
+func (this *Controller) publishOutbox() error {
+	for this.ctx.Err() == nil {
+		ctx, _ := util.GetTimeoutContext()
+		events, err := this.db.ListOutboxEvents(ctx, lifecycleBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		err = this.kafka.PublishLifecycleEvents(this.config.LifecycleTopic, events)
+		if err != nil {
+			return err
+		}
+		ids := []string{}
+		for _, event := range events {
+			ids = append(ids, event.Id)
+		}
+		ctx, _ = util.GetTimeoutContext()
+		err = this.db.RemoveOutboxEvents(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if len(events) < lifecycleBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// addLifecycleEvent adds the event of a stored change of an instance to the outbox within the transaction of ctx,
+// so it is published if and only if the change is committed. previous is nil for created and instance is nil for purged instances.
+func (this *Controller) addLifecycleEvent(ctx context.Context, userId string, previous *model.Instance, instance *model.Instance) error {
+	if this.config.LifecycleTopic == "" {
+		return nil
+	}
+	eventType, changes, ok := lifecycleTransition(previous, instance)
+	if !ok {
+		return nil
+	}
+	event := model.LifecycleEvent{Type: eventType, UserId: userId, Changes: changes}
+	if instance != nil {
+		event.InstanceId = instance.Id
+	} else {
+		event.InstanceId = previous.Id
+	}
+	return this.storeLifecycleEvent(ctx, event)
+}
+
+// addFailedLifecycleEvent adds the event of a failed instance action to the outbox.
+// Failures to store are logged only, the action has already been executed.
+func (this *Controller) addFailedLifecycleEvent(action string, userId string, instanceId string, changes []model.FieldChange, err error) {
+	if this.config.LifecycleTopic == "" || instanceId == "" || err == nil || !slices.Contains(lifecycleFailureActions, action) {
+		return
+	}
+	event := model.LifecycleEvent{Type: model.LifecycleEventFailed, Action: action, InstanceId: instanceId, UserId: userId, Changes: changes, Error: err.Error()}
+	ctx, _ := util.GetTimeoutContext()
+	dbErr := this.storeLifecycleEvent(ctx, event)
+	if dbErr != nil {
+		log.Println("ERROR: unable to add lifecycle event", action, instanceId, dbErr)
+		return
+	}
+	this.notifyLifecyclePublisher()
+}
+
+func (this *Controller) storeLifecycleEvent(ctx context.Context, event model.LifecycleEvent) (err error) {
+	event.Id, err = uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	event.Timestamp = time.Now()
+	return this.db.AddOutboxEvent(ctx, event)
+}
+
+// notifyLifecyclePublisher publishes committed events immediately instead of waiting for the next poll
+func (this *Controller) notifyLifecyclePublisher() {
+	if this.config.LifecycleTopic == "" {
+		return
+	}
+	select {
+	case this.lifecycleQueued <- struct{}{}:
+	default: // the publisher is already notified
+	}
+}
+
+// lifecycleTransition derives the lifecycle event of a stored change. Changes of the managed fields only,
+// e.g. the schedule state or a recreated workload, are no lifecycle events.
+func lifecycleTransition(previous *model.Instance, instance *model.Instance) (eventType string, changes []model.FieldChange, ok bool) {
+	switch {
+	case previous == nil:
+		return model.LifecycleEventCreated, diffFields(model.Instance{}, *instance), true
+	case instance == nil:
+		return model.LifecycleEventPurged, nil, true
+	case previous.DeletedAt == nil && instance.DeletedAt != nil:
+		return model.LifecycleEventDeleted, nil, true
+	case previous.DeletedAt != nil && instance.DeletedAt == nil:
+		return model.LifecycleEventRestored, diffFields(*previous, *instance), true
+	case !previous.Paused && instance.Paused:
+		return model.LifecycleEventPaused, nil, true
+	case previous.Paused && !instance.Paused:
+		return model.LifecycleEventResumed, nil, true
+	}
+	changes = diffFields(*previous, *instance)
+	if len(changes) > 0 || previous.KafkaGroupId != instance.KafkaGroupId {
+		return model.LifecycleEventUpdated, changes, true // a new consumer group is a replay or an offset change
+	}
+	return "", nil, false
+}
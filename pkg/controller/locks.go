@@ -0,0 +1,111 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// locks shared with other replicas are leases, extended while they are held,
+// so the lock of a stopped replica is released at most this long after it stopped
+const lockLease = time.Minute
+
+// keyedMutex serializes work on the same instance within this manager process,
+// e.g. an update removing and recreating a container while the reconciler checks it
+type keyedMutex struct {
+	mux   sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+type keyedMutexEntry struct {
+	mux   sync.Mutex
+	users int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: map[string]*keyedMutexEntry{}}
+}
+
+// Lock blocks until the key is free and returns the matching unlock function
+func (this *keyedMutex) Lock(key string) (unlock func()) {
+	this.mux.Lock()
+	entry, ok := this.locks[key]
+	if !ok {
+		entry = &keyedMutexEntry{}
+		this.locks[key] = entry
+	}
+	entry.users++
+	this.mux.Unlock()
+
+	entry.mux.Lock()
+	return func() {
+		entry.mux.Unlock()
+		this.mux.Lock()
+		entry.users--
+		if entry.users == 0 {
+			delete(this.locks, key)
+		}
+		this.mux.Unlock()
+	}
+}
+
+// holdLock extends a lock acquired with AcquireLock until the returned release function removes it.
+// extended is called after every extension attempt, locked is false if the lock has been lost to another replica.
+func (this *Controller) holdLock(key string, extended func(locked bool)) (release func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(lockLease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, _ := util.GetTimeoutContext()
+				locked, err := this.db.ExtendLock(ctx, key, this.lockOwner, time.Now().Add(lockLease))
+				if err != nil {
+					log.Println("ERROR: unable to extend lock", key, err)
+					continue
+				}
+				if !locked {
+					log.Println("WARNING: lost lock", key)
+				}
+				if extended != nil {
+					extended(locked)
+				}
+				if !locked {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		ctx, _ := util.GetTimeoutContext()
+		err := this.db.ReleaseLock(ctx, key, this.lockOwner)
+		if err != nil {
+			log.Println("ERROR: unable to release lock", key, err)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/hashicorp/go-uuid"
+)
+
+// running operations are locked for this duration and the lock is extended while they run,
+// so operations of a stopped replica are picked up by another one at most this long after it stopped
+const operationLease = 2 * time.Minute
+
+// queued operations are polled this often, operations submitted to this replica are picked up immediately
+const operationPollInterval = 5 * time.Second
+
+// an operation interrupted more often fails instead of being retried
+const operationMaxAttempts = 3
+
+// finished operations are removed at least this often if operation_retention is set
+const operationPurgeInterval = time.Hour
+
+// SubmitCreateInstance queues the creation of an instance, see CreateInstance.
+// Operations run without the token of the caller, so everything depending on it is checked before they are queued.
+func (this *Controller) SubmitCreateInstance(instance model.Instance, userId string, token string) (result model.Operation, err error, code int) {
+	id, err := newInstanceId()
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	instance.TemplateId = "" // linking requires read permission on the template, see CreateInstanceFromTemplate
+	_, _, err, code = this.prepareCreate(instance, userId, token, id)
+	if err != nil {
+		return result, err, code
+	}
+	roles := tokenRoles(token, userId)
+	err, code = this.checkQuota(roles, userId, 1) // checked again when the operation runs
+	if err != nil {
+		return result, err, code
+	}
+	return this.submitOperation(model.Operation{Type: model.OperationTypeCreate, UserId: userId, InstanceId: id, Instance: &instance, Roles: roles})
+}
+
+// SubmitSetInstance queues the update of an instance, see SetInstance
+func (this *Controller) SubmitSetInstance(instance model.Instance, userId string, token string, ifMatch []int64) (result model.Operation, err error, code int) {
+	err, code = this.checkInstancePermission(token, instance.Id, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	_, _, _, err, code = this.prepareUpdate(instance, userId, token)
+	if err != nil {
+		return result, err, code
+	}
+	return this.submitOperation(model.Operation{Type: model.OperationTypeUpdate, UserId: userId, InstanceId: instance.Id, Instance: &instance, IfMatch: ifMatch})
+}
+
+// SubmitPatchInstance queues a patch of an instance, see PatchInstance
+func (this *Controller) SubmitPatchInstance(token string, userId string, id string, patch json.RawMessage, ifMatch []int64) (result model.Operation, err error, code int) {
+	err, code = this.checkInstancePermission(token, id, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	existing, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	instance, err := mergePatchInstance(existing, patch)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	_, _, _, err, code = this.prepareUpdate(instance, userId, token)
+	if err != nil {
+		return result, err, code
+	}
+	return this.submitOperation(model.Operation{Type: model.OperationTypePatch, UserId: userId, InstanceId: id, Patch: string(patch), IfMatch: ifMatch})
+}
+
+// GetOperation returns an operation submitted by the user. Admins may read all operations.
+func (this *Controller) GetOperation(token string, userId string, id string) (result model.Operation, err error, code int) {
+	ctx, _ := util.GetTimeoutContext()
+	result, exists, err := this.db.GetOperation(ctx, id)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if result.UserId != userId {
+		if adminErr, _ := checkAdmin(token); adminErr != nil {
+			return model.Operation{}, errors.New("not found"), http.StatusNotFound
+		}
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) submitOperation(operation model.Operation) (result model.Operation, err error, code int) {
+	operation.Id, err = uuid.GenerateUUID()
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	operation.Status = model.OperationStatusPending
+	operation.CreatedAt = time.Now()
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.AddOperation(ctx, operation)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	select {
+	case this.operationQueued <- struct{}{}:
+	default: // a worker is already notified
+	}
+	return operation, nil, http.StatusAccepted
+}
+
+// StartOperationWorkers processes queued operations with operation_workers parallel workers until the controller context is done.
+// Finished operations are removed after operation_retention.
+func (this *Controller) StartOperationWorkers() error {
+	var retention time.Duration
+	if this.config.OperationRetention != "" {
+		var err error
+		retention, err = time.ParseDuration(this.config.OperationRetention)
+		if err != nil {
+			return err
+		}
+	}
+	owner, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	for range max(this.config.OperationWorkers, 1) {
+		this.wg.Add(1)
+		go func() {
+			defer this.wg.Done()
+			ticker := time.NewTicker(operationPollInterval)
+			defer ticker.Stop()
+			for {
+				this.processOperations(owner)
+				select {
+				case <-this.ctx.Done():
+					return
+				case <-ticker.C:
+				case <-this.operationQueued:
+				}
+			}
+		}()
+	}
+	if retention > 0 {
+		this.wg.Add(1)
+		go func() {
+			defer this.wg.Done()
+			ticker := time.NewTicker(min(retention, operationPurgeInterval))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-this.ctx.Done():
+					return
+				case <-ticker.C:
+					ctx, _ := util.GetTimeoutContext()
+					err := this.db.RemoveFinishedOperations(ctx, time.Now().Add(-retention))
+					if err != nil {
+						log.Println("ERROR: unable to remove finished operations:", err)
+					}
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// processOperations runs queued operations until the queue is empty or the controller context is done
+func (this *Controller) processOperations(owner string) {
+	for this.ctx.Err() == nil {
+		ctx, _ := util.GetTimeoutContext()
+		operation, found, err := this.db.ClaimOperation(ctx, owner, time.Now().Add(operationLease))
+		if err != nil {
+			log.Println("ERROR: unable to claim operation:", err)
+			return
+		}
+		if !found {
+			return
+		}
+		this.runOperation(operation, owner)
+	}
+}
+
+func (this *Controller) runOperation(operation model.Operation, owner string) {
+	stop := this.keepOperationLocked(operation.Id, owner)
+	var result *model.Instance
+	var err error
+	var code int
+	if operation.Attempts > operationMaxAttempts {
+		err, code = errors.New("operation has been interrupted too often"), http.StatusInternalServerError
+	} else {
+		result, err, code = this.executeOperation(operation)
+	}
+	stop()
+
+	now := time.Now()
+	operation.Status = model.OperationStatusSucceeded
+	operation.Code = code
+	if err != nil {
+		operation.Status = model.OperationStatusFailed
+		operation.Error = err.Error()
+	}
+	if result != nil {
+		maskInstance(result)
+		operation.Result = result
+	}
+	operation.FinishedAt = &now
+	// the request may contain credentials, it is removed from every finished operation,
+	// including those failing because they have been interrupted too often
+	operation.Instance = nil
+	operation.Patch = ""
+	operation.Roles = nil
+	operation.LockedUntil = nil
+	ctx, _ := util.GetTimeoutContext()
+	locked, err := this.db.FinishOperation(ctx, operation, owner)
+	if err != nil {
+		log.Println("ERROR: unable to store result of operation", operation.Id, err)
+		return
+	}
+	if !locked {
+		log.Println("WARN: lost lock of operation", operation.Id, "before it finished, its result is discarded")
+	}
+}
+
+// executeOperation runs the operation through the synchronous implementation of its call.
+// The caller has been authorized and its input verified when the operation was submitted, it runs with the internal admin token.
+func (this *Controller) executeOperation(operation model.Operation) (result *model.Instance, err error, code int) {
+	token := permv2client.InternalAdminToken
+	switch operation.Type {
+	case model.OperationTypeCreate:
+		if operation.Attempts > 1 {
+			// an interrupted attempt may have completed the creation already
+			ctx, _ := util.GetTimeoutContext()
+			existing, _, err := this.db.GetInstances(ctx, []string{operation.InstanceId})
+			if err != nil {
+				return nil, err, http.StatusInternalServerError
+			}
+			if len(existing) > 0 {
+				return &existing[0], nil, http.StatusOK
+			}
+		}
+		created, err, code := this.createInstance(*operation.Instance, operation.UserId, token, operation.Roles, model.AuditActionCreate, operation.InstanceId)
+		if err != nil {
+			return nil, err, code
+		}
+		return &created, nil, code
+	case model.OperationTypeUpdate:
+		err, code = this.SetInstance(*operation.Instance, operation.UserId, token, operation.IfMatch)
+		return nil, err, code
+	case model.OperationTypePatch:
+		patched, err, code := this.PatchInstance(token, operation.UserId, operation.InstanceId, json.RawMessage(operation.Patch), operation.IfMatch)
+		if err != nil {
+			return nil, err, code
+		}
+		return &patched, nil, code
+	default:
+		return nil, errors.New("unknown operation type " + operation.Type), http.StatusInternalServerError
+	}
+}
+
+// keepOperationLocked extends the lock of the running operation until the returned function is called
+func (this *Controller) keepOperationLocked(id string, owner string) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(operationLease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, _ := util.GetTimeoutContext()
+				locked, err := this.db.ExtendOperationLock(ctx, id, owner, time.Now().Add(operationLease))
+				if err != nil {
+					log.Println("ERROR: unable to extend lock of operation", id, err)
+					continue
+				}
+				if !locked {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
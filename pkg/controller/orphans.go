@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// workloads younger than this may belong to an instance which is still being created
+const orphanMinAge = 10 * time.Minute
+
+// CleanupOrphans finds workloads with the container name prefix which are not referenced by any stored instance.
+// With dryRun the orphans are only reported, otherwise they are removed.
+func (this *Controller) CleanupOrphans(token string, dryRun bool) (report model.OrphanReport, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return report, err, code
+	}
+	report = model.OrphanReport{Orphans: []model.Workload{}, Removed: []string{}, Failed: map[string]string{}}
+	serviceIds, err := this.listServiceIds()
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	workloads, err := this.deploymentClient.ListContainers(containerNamePrefix)
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	for _, workload := range workloads {
+		if serviceIds[workload.Id] || time.Since(workload.CreatedAt) < orphanMinAge {
+			continue
+		}
+		report.Orphans = append(report.Orphans, workload)
+	}
+	if dryRun {
+		return report, nil, http.StatusOK
+	}
+	for _, orphan := range report.Orphans {
+		log.Println("Removing orphaned workload " + orphan.Name)
+		err = this.deploymentClient.RemoveContainer(orphan.Id)
+		if err != nil {
+			report.Failed[orphan.Id] = err.Error()
+			continue
+		}
+		report.Removed = append(report.Removed, orphan.Id)
+	}
+	return report, nil, http.StatusOK
+}
+
+func (this *Controller) listServiceIds() (serviceIds map[string]bool, err error) {
+	serviceIds = map[string]bool{}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "id", true, "", true, nil, nil)
+		if err != nil {
+			return serviceIds, err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			serviceIds[instance.ServiceId] = true
+		}
+		if len(instances) < int(batchSize) {
+			return serviceIds, nil // done
+		}
+	}
+}
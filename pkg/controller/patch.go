@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// PatchInstance applies a JSON Merge Patch (RFC 7396) to the stored instance. Omitted fields, including the custom mqtt password,
+// are kept and null removes a field. The workload is only redeployed if the patch changes its configuration.
+// With ifMatch, the patch is only applied if the instance still has one of these revisions.
+func (this *Controller) PatchInstance(token string, userId string, id string, patch json.RawMessage, ifMatch []int64) (result model.Instance, err error, code int) {
+	var changes []model.FieldChange
+	defer func() {
+		this.audit(model.AuditActionUpdate, userId, id, changes, err)
+	}()
+	ok, err, code := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := getTimeoutContext()
+	existing, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if ifMatch != nil && !slices.Contains(ifMatch, existing.Revision) {
+		return result, errRevisionConflict, http.StatusPreconditionFailed
+	}
+	instance, err := mergePatchInstance(existing, patch)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	// the patch is based on the loaded revision, changes of other replicas since then must not be overwritten
+	result, changes, err, code = this.updateInstance(instance, userId, token, []int64{existing.Revision})
+	if err != nil {
+		return result, err, code
+	}
+	result.Status = this.getDeploymentStatus(result)
+	return result, nil, http.StatusOK
+}
+
+func mergePatchInstance(instance model.Instance, patch json.RawMessage) (result model.Instance, err error) {
+	var patchValue interface{}
+	err = json.Unmarshal(patch, &patchValue)
+	if err != nil {
+		return result, err
+	}
+	if _, ok := patchValue.(map[string]interface{}); !ok {
+		return result, errors.New("expected a json object as merge patch")
+	}
+	b, err := json.Marshal(instance)
+	if err != nil {
+		return result, err
+	}
+	var target interface{}
+	err = json.Unmarshal(b, &target)
+	if err != nil {
+		return result, err
+	}
+	b, err = json.Marshal(mergePatch(target, patchValue))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		return result, err
+	}
+	result.Id = instance.Id // the id is taken from the path
+	return result, nil
+}
+
+// mergePatch implements the merge algorithm of RFC 7396 on decoded json values
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+func TestMergePatchInstance(t *testing.T) {
+	ptr := func(value string) *string {
+		return &value
+	}
+	base := func() model.Instance {
+		return model.Instance{
+			Id:                 "instance",
+			Name:               "name",
+			Description:        "description",
+			Topic:              "topic",
+			Values:             []model.Value{{Name: "a", Path: "value.a"}, {Name: "b", Path: "value.b"}},
+			CustomMqttBroker:   ptr("tcp://broker:1883"),
+			CustomMqttUser:     ptr("user"),
+			CustomMqttPassword: ptr("secret"),
+			Schedule: &model.Schedule{
+				TimeZone: "Europe/Berlin",
+				Windows:  []model.ScheduleWindow{{Days: []string{"mon"}, From: "08:00", To: "17:00"}},
+			},
+			Revision:  3,
+			CreatedAt: time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	tests := []struct {
+		name     string
+		patch    string
+		expected func(instance *model.Instance)
+	}{
+		{"empty patch", `{}`, func(instance *model.Instance) {}},
+		{"replace value", `{"Name": "other"}`, func(instance *model.Instance) {
+			instance.Name = "other"
+		}},
+		{"omitted password is kept", `{"CustomMqttUser": "other"}`, func(instance *model.Instance) {
+			instance.CustomMqttUser = ptr("other")
+		}},
+		{"null removes value", `{"Description": null, "CustomMqttPassword": null}`, func(instance *model.Instance) {
+			instance.Description = ""
+			instance.CustomMqttPassword = nil
+		}},
+		{"null of missing value", `{"ImageVersion": null}`, func(instance *model.Instance) {}},
+		{"array is replaced", `{"Values": [{"Name": "c", "Path": "value.c"}]}`, func(instance *model.Instance) {
+			instance.Values = []model.Value{{Name: "c", Path: "value.c"}}
+		}},
+		{"empty array", `{"Values": []}`, func(instance *model.Instance) {
+			instance.Values = []model.Value{}
+		}},
+		{"null removes array", `{"Values": null}`, func(instance *model.Instance) {
+			instance.Values = nil
+		}},
+		{"object is merged", `{"Schedule": {"TimeZone": "UTC"}}`, func(instance *model.Instance) {
+			instance.Schedule.TimeZone = "UTC"
+		}},
+		{"array in object is replaced", `{"Schedule": {"Windows": [{"From": "22:00", "To": "06:00"}]}}`, func(instance *model.Instance) {
+			instance.Schedule.Windows = []model.ScheduleWindow{{From: "22:00", To: "06:00"}}
+		}},
+		{"null in object removes value", `{"Schedule": {"TimeZone": null}}`, func(instance *model.Instance) {
+			instance.Schedule.TimeZone = ""
+		}},
+		{"null removes object", `{"Schedule": null}`, func(instance *model.Instance) {
+			instance.Schedule = nil
+		}},
+		{"id is kept", `{"ID": "other"}`, func(instance *model.Instance) {}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := base()
+			test.expected(&expected)
+			actual, err := mergePatchInstance(base(), json.RawMessage(test.patch))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("\nexpected %#v\ngot      %#v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestMergePatchInstanceInvalid(t *testing.T) {
+	for _, patch := range []string{`null`, `[]`, `"name"`, `1`, `{`, `{"Name": 1}`} {
+		t.Run(patch, func(t *testing.T) {
+			_, err := mergePatchInstance(model.Instance{Id: "instance"}, json.RawMessage(patch))
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// a quota lock held by another replica is polled this often
+const quotaLockPollInterval = 200 * time.Millisecond
+
+// GetQuotaUsage returns the number of instances of the requesting user and the limit effective for them
+func (this *Controller) GetQuotaUsage(token string, userId string) (result model.QuotaUsage, err error, code int) {
+	return this.quotaUsage(tokenRoles(token, userId), userId)
+}
+
+func (this *Controller) ListQuotas(token string) (result []model.Quota, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return nil, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	result, err = this.db.ListQuotas(ctx)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// SetQuota creates or replaces the override of a user or role
+func (this *Controller) SetQuota(token string, userId string, quota model.Quota) (err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return err, code
+	}
+	defer func() {
+		this.addAuditEvent(model.AuditEvent{Action: model.AuditActionQuota, UserId: userId, Changes: []model.FieldChange{{Field: "Quota", To: quota}}}, err)
+	}()
+	if quota.Type != model.QuotaTypeUser && quota.Type != model.QuotaTypeRole {
+		return errors.New("unknown quota type, expected " + model.QuotaTypeUser + " or " + model.QuotaTypeRole), http.StatusBadRequest
+	}
+	if quota.Subject == "" {
+		return errors.New("missing quota subject"), http.StatusBadRequest
+	}
+	if quota.Limit < 0 {
+		return errors.New("quota limit must not be negative"), http.StatusBadRequest
+	}
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.SetQuota(ctx, quota)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusNoContent
+}
+
+// RemoveQuota removes the override of a user or role, the default_instance_quota or other overrides apply again
+func (this *Controller) RemoveQuota(token string, userId string, quotaType string, subject string) (err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return err, code
+	}
+	defer func() {
+		this.addAuditEvent(model.AuditEvent{Action: model.AuditActionQuota, UserId: userId, Changes: []model.FieldChange{{Field: "Quota", From: model.Quota{Type: quotaType, Subject: subject}}}}, err)
+	}()
+	ctx, _ := util.GetTimeoutContext()
+	exists, err := this.db.RemoveQuota(ctx, quotaType, subject)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if !exists {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	return nil, http.StatusNoContent
+}
+
+// checkQuota rejects creating additional instances for the user beyond the effective limit.
+// Callers hold the lockQuota lock of the user until the instances are stored.
+func (this *Controller) checkQuota(roles []string, userId string, additional int64) (err error, code int) {
+	usage, err, code := this.quotaUsage(roles, userId)
+	if err != nil {
+		return err, code
+	}
+	if usage.Limit > 0 && usage.Used+additional > usage.Limit {
+		return fmt.Errorf("instance quota exceeded: %v of %v instances in use", usage.Used, usage.Limit), http.StatusTooManyRequests
+	}
+	return nil, http.StatusOK
+}
+
+// quotaUsage applies the overrides of the user and of roles, see tokenRoles
+func (this *Controller) quotaUsage(roles []string, userId string) (result model.QuotaUsage, err error, code int) {
+	result = model.QuotaUsage{UserId: userId, Limit: max(this.config.DefaultInstanceQuota, 0), Source: model.QuotaSourceDefault}
+	ctx, _ := util.GetTimeoutContext()
+	quotas, err := this.db.GetQuotas(ctx, userId, roles)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	applyQuotas(&result, quotas)
+	ctx, _ = util.GetTimeoutContext()
+	result.Used, err = this.db.CountInstances(ctx, userId)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// lockQuota blocks until the quota lock of the user is held by this process and returns the matching unlock function.
+// The lock is shared with all replicas, so concurrent creations on different replicas can not exceed the quota together.
+func (this *Controller) lockQuota(userId string) (unlock func(), err error) {
+	unlockLocal := this.quotaLocks.Lock(userId)
+	key := "quota:" + userId
+	deadline := time.Now().Add(lockLease)
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		acquired, err := this.db.AcquireLock(ctx, key, this.lockOwner, time.Now().Add(lockLease))
+		if err != nil {
+			unlockLocal()
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			unlockLocal()
+			return nil, errors.New("timeout while waiting for the quota lock of " + userId)
+		}
+		time.Sleep(quotaLockPollInterval)
+	}
+	release := this.holdLock(key, nil)
+	return func() {
+		release()
+		unlockLocal()
+	}, nil
+}
+
+// applyQuotas sets the effective limit. A user override wins over role overrides, of which the most generous one applies.
+func applyQuotas(usage *model.QuotaUsage, quotas []model.Quota) {
+	roleApplied := false
+	for _, quota := range quotas {
+		switch quota.Type {
+		case model.QuotaTypeUser:
+			usage.Limit, usage.Source, usage.Role = quota.Limit, model.QuotaTypeUser, ""
+			return
+		case model.QuotaTypeRole:
+			if !roleApplied || moreGenerous(quota.Limit, usage.Limit) {
+				usage.Limit, usage.Source, usage.Role = quota.Limit, model.QuotaTypeRole, quota.Subject
+				roleApplied = true
+			}
+		}
+	}
+}
+
+// moreGenerous compares limits where 0 is unlimited
+func moreGenerous(limit int64, than int64) bool {
+	if than == 0 {
+		return false
+	}
+	return limit == 0 || limit > than
+}
+
+// tokenRoles returns the roles of the token if it belongs to the user. Role overrides do not apply to other users,
+// e.g. when an admin restores an instance of someone else.
+func tokenRoles(token string, userId string) []string {
+	parsed, err := jwt.Parse(token)
+	if err != nil || parsed.GetUserId() != userId {
+		return []string{}
+	}
+	return parsed.GetRoles()
+}
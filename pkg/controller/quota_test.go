@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+func TestApplyQuotas(t *testing.T) {
+	user := func(limit int64) model.Quota {
+		return model.Quota{Type: model.QuotaTypeUser, Subject: "user", Limit: limit}
+	}
+	role := func(subject string, limit int64) model.Quota {
+		return model.Quota{Type: model.QuotaTypeRole, Subject: subject, Limit: limit}
+	}
+	tests := []struct {
+		name     string
+		quotas   []model.Quota
+		expected model.QuotaUsage
+	}{
+		{"default", nil, model.QuotaUsage{Limit: 10, Source: model.QuotaSourceDefault}},
+		{"user override", []model.Quota{user(3)}, model.QuotaUsage{Limit: 3, Source: model.QuotaTypeUser}},
+		{"unlimited user override", []model.Quota{user(0)}, model.QuotaUsage{Limit: 0, Source: model.QuotaTypeUser}},
+		{"role override", []model.Quota{role("a", 5)}, model.QuotaUsage{Limit: 5, Source: model.QuotaTypeRole, Role: "a"}},
+		{"role override stricter than default", []model.Quota{role("a", 2)}, model.QuotaUsage{Limit: 2, Source: model.QuotaTypeRole, Role: "a"}},
+		{"most generous role", []model.Quota{role("a", 5), role("b", 20), role("c", 7)}, model.QuotaUsage{Limit: 20, Source: model.QuotaTypeRole, Role: "b"}},
+		{"unlimited role", []model.Quota{role("a", 5), role("b", 0), role("c", 7)}, model.QuotaUsage{Limit: 0, Source: model.QuotaTypeRole, Role: "b"}},
+		{"first unlimited role is kept", []model.Quota{role("a", 0), role("b", 0)}, model.QuotaUsage{Limit: 0, Source: model.QuotaTypeRole, Role: "a"}},
+		{"user override wins over roles", []model.Quota{role("a", 50), user(3), role("b", 0)}, model.QuotaUsage{Limit: 3, Source: model.QuotaTypeUser}},
+		{"user override after roles", []model.Quota{role("a", 0), user(3)}, model.QuotaUsage{Limit: 3, Source: model.QuotaTypeUser}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usage := model.QuotaUsage{Limit: 10, Source: model.QuotaSourceDefault}
+			applyQuotas(&usage, test.quotas)
+			if usage != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, usage)
+			}
+		})
+	}
+}
+
+func TestMoreGenerous(t *testing.T) {
+	tests := []struct {
+		limit    int64
+		than     int64
+		expected bool
+	}{
+		{5, 3, true},
+		{3, 5, false},
+		{3, 3, false},
+		{0, 3, true},
+		{3, 0, false},
+		{0, 0, false},
+	}
+	for _, test := range tests {
+		actual := moreGenerous(test.limit, test.than)
+		if actual != test.expected {
+			t.Errorf("moreGenerous(%v, %v): expected %v, got %v", test.limit, test.than, test.expected, actual)
+		}
+	}
+}
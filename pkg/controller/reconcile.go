@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+func (this *Controller) EnsureAllInstancesDeployed() (err error) {
+	_, err = this.reconcile(true)
+	return err
+}
+
+// StartReconcileLoop periodically retries failed compensations and recreates missing workloads until the controller context is done.
+// With reconcile_redeploy_drift, drifted workloads are redeployed as well.
+// The loop is disabled if reconcile_interval is empty or not positive.
+func (this *Controller) StartReconcileLoop() error {
+	if this.config.ReconcileInterval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(this.config.ReconcileInterval)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return nil
+	}
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+				this.logRetriedCompensations()
+				report, err := this.reconcile(false)
+				if err != nil {
+					log.Println("ERROR: reconciliation aborted:", err)
+				}
+				if len(report.Recreated) > 0 || len(report.Failed) > 0 {
+					log.Printf("reconciliation checked %v instances, recreated %v, failed %v\n", report.Checked, report.Recreated, report.Failed)
+				} else if this.config.Debug {
+					log.Printf("DEBUG: reconciliation checked %v instances, nothing to do\n", report.Checked)
+				}
+				if this.config.ReconcileRedeployDrift && this.ctx.Err() == nil {
+					this.redeployDrift()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// reconcile recreates the workloads of all instances missing in the deployment backend. Paused instances are skipped.
+// With failFast the first error aborts the run, otherwise failures are collected in the report.
+func (this *Controller) reconcile(failFast bool) (report model.ReconcileReport, err error) {
+	report = model.ReconcileReport{Recreated: []string{}, Failed: map[string]string{}}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "name", true, "", true, nil, nil)
+		if err != nil {
+			return report, err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			if this.ctx.Err() != nil {
+				return report, this.ctx.Err()
+			}
+			report.Checked++
+			recreated, err := this.ensureInstanceDeployed(instance.Id)
+			if err != nil {
+				if failFast {
+					return report, err
+				}
+				log.Println("ERROR: unable to reconcile", instance.Id, err)
+				report.Failed[instance.Id] = err.Error()
+				continue
+			}
+			if recreated {
+				report.Recreated = append(report.Recreated, instance.Id)
+			}
+		}
+		if len(instances) < int(batchSize) {
+			return report, nil // done
+		}
+	}
+}
+
+func (this *Controller) ensureInstanceDeployed(id string) (recreated bool, err error) {
+	defer this.instanceLocks.Lock(id)()
+	// reload instance to see changes made while waiting for the lock
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if instance.Paused {
+		if this.config.Debug {
+			log.Println(instance.Id + " is paused")
+		}
+		return false, this.ensureWorkloadStopped(instance)
+	}
+	exists, err = this.deploymentClient.ContainerExists(instance.ServiceId)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		if this.config.Debug {
+			log.Println(instance.Id + " still exists")
+		}
+		if !shouldRun(instance) {
+			return false, this.ensureWorkloadStopped(instance)
+		}
+		return false, nil
+	}
+	log.Println("Recreating " + instance.Id)
+	loaded := instance
+	env, err, _ := this.getEnv(&instance, "", instance.UserId, false)
+	if err != nil {
+		return false, err
+	}
+	instance.ServiceId, err = this.deploymentClient.CreateContainer(containerName(instance), this.getImage(instance), instance.UserId, env, true)
+	if err != nil {
+		return false, err
+	}
+	if !shouldRun(instance) {
+		err = this.deploymentClient.StopContainer(instance.ServiceId)
+		if err != nil {
+			return true, err
+		}
+	}
+	err = this.storeInstanceChange(&instance, "", loaded)
+	if errors.Is(err, errRevisionConflict) {
+		this.redeployStored(instance, instance.KafkaGroupId)
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// ensureWorkloadStopped stops the workload of an instance which is not expected to run if it runs anyway,
+// e.g. a docker container created with the restart policy always after a restart of the docker daemon
+func (this *Controller) ensureWorkloadStopped(instance model.Instance) error {
+	if instance.ServiceId == "" {
+		return nil
+	}
+	status, err := this.deploymentClient.GetContainerStatus(instance.ServiceId)
+	if err != nil {
+		return err
+	}
+	switch status.State {
+	case model.DeploymentStateRunning, model.DeploymentStateRestarting, model.DeploymentStateCrashLoop:
+		log.Println("Stopping " + instance.Id + ", its workload runs although it should not")
+		return this.deploymentClient.StopContainer(instance.ServiceId)
+	default:
+		return nil
+	}
+}
+
+func (this *Controller) redeployDrift() {
+	report, err := this.detectDrift(true)
+	if err != nil {
+		log.Println("ERROR: drift detection aborted:", err)
+	}
+	for _, drift := range report.Drifted {
+		if drift.Redeployed {
+			log.Println("redeployed drifted instance", drift.InstanceId)
+		} else if drift.Error != "" {
+			log.Println("ERROR: unable to handle drifted instance", drift.InstanceId, drift.Error)
+		}
+	}
+}
+
+func (this *Controller) logRetriedCompensations() {
+	report, err := this.retryCompensations()
+	if err != nil {
+		log.Println("ERROR: retrying compensations aborted:", err)
+	}
+	if len(report.Resolved) > 0 || len(report.Failed) > 0 {
+		log.Printf("retried %v compensations, resolved %v, failed %v\n", report.Retried, report.Resolved, report.Failed)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// ReplayInstance redeploys the instance with a new consumer group whose offsets are committed before the worker starts.
+// The worker only understands smallest and largest as offset, so the position is set through the group instead of the worker configuration.
+func (this *Controller) ReplayInstance(token string, userId string, id string, request model.ReplayRequest) (result model.ReplayResult, err error, code int) {
+	defer func() {
+		this.audit(model.AuditActionReplay, userId, id, []model.FieldChange{{Field: "Offsets", To: result.Offsets}}, err)
+	}()
+	ok, err, code := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Execute)
+	if err != nil {
+		return result, err, code
+	}
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if (request.From == nil) == (len(request.Offsets) == 0) {
+		return result, errors.New("expected either From or Offsets"), http.StatusBadRequest
+	}
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+
+	first, end, err := this.kafka.GetOffsetRanges(instance.Topic)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	err = verifyReplayOffsets(request.Offsets, first, end)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	existing := instance
+	image, previousGroupId := this.deployedVersion(existing)
+	if previousGroupId == "" {
+		previousGroupId = instance.Id
+	}
+	env, err, _ := this.getEnv(&instance, "", instance.UserId, false)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	err = refreshConsumerGroupId(&instance, env)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	groupId := instance.KafkaGroupId
+
+	// the worker is stopped first, so partitions without requested offset continue exactly where it stopped
+	if shouldRun(instance) {
+		err = this.deploymentClient.StopContainer(instance.ServiceId)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+	}
+	offsets, err := this.replayOffsets(instance, previousGroupId, request, first, end)
+	if err == nil {
+		err = this.kafka.CommitOffsets(groupId, instance.Topic, offsets)
+	}
+	if err != nil {
+		if shouldRun(instance) {
+			startErr := this.deploymentClient.StartContainer(instance.ServiceId)
+			if startErr != nil {
+				log.Println("ERROR: unable to restart", instance.Id, "after failed replay:", startErr)
+			}
+		}
+		return result, err, http.StatusInternalServerError
+	}
+
+	err = this.redeploy(&instance, image, env)
+	if err == nil {
+		err = this.storeInstanceChange(&instance, userId, existing)
+	}
+	if errors.Is(err, errRevisionConflict) {
+		this.redeployStored(instance, previousGroupId)
+		return result, err, http.StatusPreconditionFailed
+	}
+	if err != nil {
+		this.rollbackUpdate(existing, instance.ServiceId, image, previousGroupId)
+		return result, err, http.StatusInternalServerError
+	}
+
+	result = model.ReplayResult{KafkaGroupId: groupId, Offsets: []model.PartitionOffset{}}
+	for partition, offset := range offsets {
+		result.Offsets = append(result.Offsets, model.PartitionOffset{Partition: partition, Offset: offset})
+	}
+	slices.SortFunc(result.Offsets, func(a, b model.PartitionOffset) int {
+		return a.Partition - b.Partition
+	})
+	return result, nil, http.StatusOK
+}
+
+// replayOffsets returns the start offset of every partition. Partitions without requested offset continue at the position
+// of the previous consumer group or, without committed offset, where the configured Offset would start.
+func (this *Controller) replayOffsets(instance model.Instance, previousGroupId string, request model.ReplayRequest, first map[int]int64, end map[int]int64) (offsets map[int]int64, err error) {
+	if request.From != nil {
+		return this.kafka.GetOffsetsForTime(instance.Topic, *request.From)
+	}
+	committed, err := this.kafka.GetCommittedOffsets(previousGroupId, instance.Topic)
+	if err != nil {
+		return nil, err
+	}
+	offsets = map[int]int64{}
+	for partition := range end {
+		offset, ok := committed[partition]
+		switch {
+		case ok:
+			offsets[partition] = offset
+		case instance.Offset == "smallest":
+			offsets[partition] = first[partition]
+		default:
+			offsets[partition] = end[partition]
+		}
+	}
+	for _, requested := range request.Offsets {
+		offsets[requested.Partition] = requested.Offset
+	}
+	return offsets, nil
+}
+
+func verifyReplayOffsets(requested []model.PartitionOffset, first map[int]int64, end map[int]int64) error {
+	seen := map[int]bool{}
+	for _, offset := range requested {
+		if _, ok := end[offset.Partition]; !ok {
+			return fmt.Errorf("unknown partition %v", offset.Partition)
+		}
+		if seen[offset.Partition] {
+			return fmt.Errorf("duplicate offset for partition %v", offset.Partition)
+		}
+		seen[offset.Partition] = true
+		if offset.Offset < first[offset.Partition] || offset.Offset > end[offset.Partition] {
+			return fmt.Errorf("offset %v of partition %v is outside of the available range %v to %v", offset.Offset, offset.Partition, first[offset.Partition], end[offset.Partition])
+		}
+	}
+	return nil
+}
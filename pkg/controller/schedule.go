@@ -0,0 +1,199 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// schedules are evaluated with minute precision
+const scheduleInterval = time.Minute
+
+const timeOfDayLayout = "15:04"
+
+var scheduleDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"} // index is time.Weekday
+
+// StartScheduler periodically starts and stops the workloads of scheduled instances until the controller context is done
+func (this *Controller) StartScheduler() {
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+				err := this.applySchedules()
+				if err != nil {
+					log.Println("ERROR: applying schedules aborted:", err)
+				}
+			}
+		}
+	}()
+}
+
+func (this *Controller) applySchedules() error {
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "id", true, "", true, nil, nil)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			if this.ctx.Err() != nil {
+				return this.ctx.Err()
+			}
+			if instance.Schedule == nil {
+				continue
+			}
+			err = this.applySchedule(instance.Id)
+			if err != nil {
+				log.Println("ERROR: unable to apply schedule of", instance.Id, err)
+			}
+		}
+		if len(instances) < int(batchSize) {
+			return nil // done
+		}
+	}
+}
+
+// applySchedule starts or stops the workload of the instance if its schedule state changed. Paused instances stay stopped.
+func (this *Controller) applySchedule(id string) error {
+	defer this.instanceLocks.Lock(id)()
+	// reload instance to see changes made while waiting for the lock
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state := scheduleState(instance.Schedule, time.Now())
+	if state == instance.ScheduleState {
+		return nil
+	}
+	loaded := instance
+	instance.ScheduleState = state
+	if !instance.Paused {
+		if shouldRun(instance) {
+			log.Println("Starting scheduled instance " + instance.Id)
+			err = this.deploymentClient.StartContainer(instance.ServiceId)
+		} else {
+			log.Println("Stopping scheduled instance " + instance.Id)
+			err = this.deploymentClient.StopContainer(instance.ServiceId)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return this.storeRunState(instance, "", loaded)
+}
+
+// shouldRun reports if the workload of the instance is expected to be running
+func shouldRun(instance model.Instance) bool {
+	return !instance.Paused && instance.ScheduleState != model.ScheduleStateInactive
+}
+
+// scheduleState returns the state of the schedule at the given time, or an empty string without a schedule.
+// The schedule is expected to be verified.
+func scheduleState(schedule *model.Schedule, now time.Time) string {
+	if schedule == nil {
+		return ""
+	}
+	if schedule.Start != nil && now.Before(*schedule.Start) {
+		return model.ScheduleStateInactive
+	}
+	if schedule.End != nil && !now.Before(*schedule.End) {
+		return model.ScheduleStateInactive
+	}
+	if len(schedule.Windows) == 0 {
+		return model.ScheduleStateActive
+	}
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	today := scheduleDays[local.Weekday()]
+	yesterday := scheduleDays[local.AddDate(0, 0, -1).Weekday()]
+	for _, window := range schedule.Windows {
+		from, to := minuteOfDay(window.From), minuteOfDay(window.To)
+		onDay := func(day string) bool {
+			return len(window.Days) == 0 || slices.Contains(window.Days, day)
+		}
+		if from < to {
+			if onDay(today) && minute >= from && minute < to {
+				return model.ScheduleStateActive
+			}
+			continue
+		}
+		// the window ends on the next day, equal times span the whole day
+		if (onDay(today) && minute >= from) || (onDay(yesterday) && minute < to) {
+			return model.ScheduleStateActive
+		}
+	}
+	return model.ScheduleStateInactive
+}
+
+func minuteOfDay(value string) int {
+	t, err := time.Parse(timeOfDayLayout, value)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+func verifySchedule(schedule *model.Schedule) error {
+	if schedule == nil {
+		return nil
+	}
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		return fmt.Errorf("invalid schedule time zone: %w", err)
+	}
+	if schedule.Start != nil && schedule.End != nil && !schedule.Start.Before(*schedule.End) {
+		return errors.New("schedule start must be before its end")
+	}
+	for _, window := range schedule.Windows {
+		if _, err := time.Parse(timeOfDayLayout, window.From); err != nil {
+			return fmt.Errorf("invalid schedule window start %v, expected format HH:MM", window.From)
+		}
+		if _, err := time.Parse(timeOfDayLayout, window.To); err != nil {
+			return fmt.Errorf("invalid schedule window end %v, expected format HH:MM", window.To)
+		}
+		for _, day := range window.Days {
+			if !slices.Contains(scheduleDays, day) {
+				return fmt.Errorf("invalid schedule window day %v, expected one of %v", day, scheduleDays)
+			}
+		}
+	}
+	return nil
+}
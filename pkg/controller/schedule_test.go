@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+func TestScheduleState(t *testing.T) {
+	at := func(value string) time.Time {
+		result, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	ptr := func(value time.Time) *time.Time {
+		return &value
+	}
+	daily := &model.Schedule{Windows: []model.ScheduleWindow{{From: "08:00", To: "17:00"}}}
+	overnight := &model.Schedule{Windows: []model.ScheduleWindow{{Days: []string{"mon"}, From: "22:00", To: "06:00"}}}
+	wholeDay := &model.Schedule{Windows: []model.ScheduleWindow{{Days: []string{"mon"}, From: "00:00", To: "00:00"}}}
+	berlin := &model.Schedule{TimeZone: "Europe/Berlin", Windows: []model.ScheduleWindow{{From: "08:00", To: "09:00"}}}
+	newYork := &model.Schedule{TimeZone: "America/New_York", Windows: []model.ScheduleWindow{{Days: []string{"mon"}, From: "22:00", To: "23:00"}}}
+	period := &model.Schedule{Start: ptr(at("2025-01-06T00:00:00Z")), End: ptr(at("2025-01-07T00:00:00Z"))}
+
+	// 2025-01-06 is a monday
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		now      string
+		expected string
+	}{
+		{"no schedule", nil, "2025-01-06T12:00:00Z", ""},
+		{"no windows", &model.Schedule{}, "2025-01-06T12:00:00Z", model.ScheduleStateActive},
+		{"before start", period, "2025-01-05T23:59:00Z", model.ScheduleStateInactive},
+		{"at start", period, "2025-01-06T00:00:00Z", model.ScheduleStateActive},
+		{"before end", period, "2025-01-06T23:59:00Z", model.ScheduleStateActive},
+		{"at end", period, "2025-01-07T00:00:00Z", model.ScheduleStateInactive},
+		{"before daily window", daily, "2025-01-06T07:59:00Z", model.ScheduleStateInactive},
+		{"at daily window start", daily, "2025-01-06T08:00:00Z", model.ScheduleStateActive},
+		{"at daily window end", daily, "2025-01-06T17:00:00Z", model.ScheduleStateInactive},
+		{"overnight window on start day", overnight, "2025-01-06T23:00:00Z", model.ScheduleStateActive},
+		{"overnight window on next day", overnight, "2025-01-07T05:59:00Z", model.ScheduleStateActive},
+		{"overnight window end", overnight, "2025-01-07T06:00:00Z", model.ScheduleStateInactive},
+		{"overnight window not started on previous day", overnight, "2025-01-06T05:00:00Z", model.ScheduleStateInactive},
+		{"overnight window on other day", overnight, "2025-01-07T23:00:00Z", model.ScheduleStateInactive},
+		{"whole day window start", wholeDay, "2025-01-06T00:00:00Z", model.ScheduleStateActive},
+		{"whole day window last minute", wholeDay, "2025-01-06T23:59:00Z", model.ScheduleStateActive},
+		{"whole day window end", wholeDay, "2025-01-07T00:00:00Z", model.ScheduleStateInactive},
+		{"time zone in winter", berlin, "2025-01-06T07:30:00Z", model.ScheduleStateActive},
+		{"time zone in winter outside", berlin, "2025-01-06T08:30:00Z", model.ScheduleStateInactive},
+		{"time zone in summer", berlin, "2025-07-07T06:30:00Z", model.ScheduleStateActive},
+		{"time zone in summer outside", berlin, "2025-07-07T07:30:00Z", model.ScheduleStateInactive},
+		{"time zone on previous day", newYork, "2025-01-07T03:30:00Z", model.ScheduleStateActive},
+		{"time zone on same utc day", newYork, "2025-01-06T22:30:00Z", model.ScheduleStateInactive},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := scheduleState(test.schedule, at(test.now))
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVerifySchedule(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tests := []struct {
+		name     string
+		schedule *model.Schedule
+		valid    bool
+	}{
+		{"no schedule", nil, true},
+		{"default time zone", &model.Schedule{Windows: []model.ScheduleWindow{{From: "22:00", To: "06:00"}}}, true},
+		{"valid", &model.Schedule{TimeZone: "Europe/Berlin", Start: &start, End: &end, Windows: []model.ScheduleWindow{{Days: []string{"mon", "sun"}, From: "08:00", To: "17:30"}}}, true},
+		{"unknown time zone", &model.Schedule{TimeZone: "Europe/Nowhere"}, false},
+		{"end before start", &model.Schedule{Start: &end, End: &start}, false},
+		{"end equals start", &model.Schedule{Start: &start, End: &start}, false},
+		{"invalid window start", &model.Schedule{Windows: []model.ScheduleWindow{{From: "24:00", To: "06:00"}}}, false},
+		{"invalid window end", &model.Schedule{Windows: []model.ScheduleWindow{{From: "22:00", To: "6pm"}}}, false},
+		{"window with seconds", &model.Schedule{Windows: []model.ScheduleWindow{{From: "22:00:00", To: "06:00"}}}, false},
+		{"invalid day", &model.Schedule{Windows: []model.ScheduleWindow{{Days: []string{"monday"}, From: "22:00", To: "06:00"}}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verifySchedule(test.schedule)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
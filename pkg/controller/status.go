@@ -0,0 +1,52 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"log"
+	"sync"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+// limits parallel requests to the deployment backend when listing instances
+const statusQueryConcurrency = 10
+
+// getDeploymentStatus never fails, backend errors are reported as unknown state
+func (this *Controller) getDeploymentStatus(instance model.Instance) *model.DeploymentStatus {
+	status, err := this.deploymentClient.GetContainerStatus(instance.ServiceId)
+	if err != nil {
+		log.Println("WARN: unable to get deployment status of", instance.Id, err)
+		status = model.DeploymentStatus{State: model.DeploymentStateUnknown, Message: err.Error()}
+	}
+	return &status
+}
+
+func (this *Controller) addDeploymentStatus(instances []model.Instance) {
+	wg := sync.WaitGroup{}
+	limiter := make(chan struct{}, statusQueryConcurrency)
+	for i := range instances {
+		wg.Add(1)
+		limiter <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-limiter }()
+			instances[i].Status = this.getDeploymentStatus(instances[i])
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/hashicorp/go-uuid"
+)
+
+const templateIdPrefix = "urn:infai:ses:broker-export-template:"
+
+func (this *Controller) ListTemplates(token string, limit int64, offset int64) (results []model.Template, total int, err error, errCode int) {
+	ids, err, errCode := this.permv2.ListAccessibleResourceIds(token, Permv2TemplateTopic, permv2.ListOptions{}, permv2.Read)
+	if err != nil {
+		return nil, 0, err, errCode
+	}
+	ctx, _ := util.GetTimeoutContext()
+	results, err = this.db.ListTemplates(ctx, limit, offset, ids)
+	if err != nil {
+		return nil, 0, err, http.StatusInternalServerError
+	}
+	for i := range results {
+		maskTemplate(&results[i])
+	}
+	return results, len(ids), nil, http.StatusOK
+}
+
+// ReadTemplate returns the template with a masked CustomMqttPassword
+func (this *Controller) ReadTemplate(token string, id string) (result model.Template, err error, errCode int) {
+	result, err, errCode = this.readTemplate(token, id, permv2.Read)
+	if err != nil {
+		return result, err, errCode
+	}
+	maskTemplate(&result)
+	return result, nil, errCode
+}
+
+func (this *Controller) readTemplate(token string, id string, permission permv2.Permission) (result model.Template, err error, errCode int) {
+	ok, err, errCode := this.permv2.CheckPermission(token, Permv2TemplateTopic, id, permission)
+	if err != nil {
+		return result, err, errCode
+	}
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	ctx, _ := util.GetTimeoutContext()
+	result, exists, err := this.db.GetTemplate(ctx, id)
+	if !exists {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) CreateTemplate(template model.Template, userId string, token string) (result model.Template, err error, code int) {
+	defer func() {
+		this.auditTemplate(model.AuditActionTemplateCreate, userId, result.Id, diffFields(model.Template{}, result), err)
+	}()
+	if template.Id != "" {
+		return result, errors.New("explicit setting of id not allowed"), http.StatusBadRequest
+	}
+	err, code = this.verifyTemplate(template)
+	if err != nil {
+		return result, err, code
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	template.Id = templateIdPrefix + id
+	template.UserId = userId
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.SetTemplate(ctx, template)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	_, err, code = this.permv2.SetPermission(token, Permv2TemplateTopic, template.Id, permv2.ResourcePermissions{
+		UserPermissions: map[string]permv2.PermissionsMap{
+			userId: {
+				Read:         true,
+				Write:        true,
+				Execute:      true,
+				Administrate: true,
+			},
+		},
+		RolePermissions: map[string]permv2.PermissionsMap{
+			"admin": {
+				Read:         true,
+				Write:        true,
+				Execute:      true,
+				Administrate: true,
+			},
+		},
+	})
+	if err != nil {
+		ctx, _ = util.GetTimeoutContext()
+		removeErr := this.db.RemoveTemplate(ctx, template.Id)
+		if removeErr != nil {
+			log.Println("ERROR: unable to remove template", template.Id, "after failed permission update:", removeErr)
+		}
+		if code < http.StatusBadRequest {
+			code = http.StatusInternalServerError
+		}
+		return result, err, code
+	}
+	return template, nil, http.StatusOK
+}
+
+// SetTemplate updates a template. With propagate, all linked instances the caller may write are redeployed with the new settings.
+func (this *Controller) SetTemplate(template model.Template, userId string, token string, propagate bool) (report model.TemplatePropagationReport, err error, code int) {
+	var changes []model.FieldChange
+	defer func() {
+		this.auditTemplate(model.AuditActionTemplateUpdate, userId, template.Id, changes, err)
+	}()
+	existing, err, code := this.readTemplate(token, template.Id, permv2.Write)
+	if err != nil {
+		return report, err, code
+	}
+	if template.CustomMqttPassword != nil && existing.CustomMqttPassword != nil && *template.CustomMqttPassword == "***" {
+		template.CustomMqttPassword = existing.CustomMqttPassword // unchanged value of a masked read
+	}
+	changes = diffFields(existing, template)
+	err, code = this.verifyTemplate(template)
+	if err != nil {
+		return report, err, code
+	}
+	template.UserId = existing.UserId
+	template.CreatedAt = existing.CreatedAt
+	template.UpdatedAt = time.Now()
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.SetTemplate(ctx, template)
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	report = model.TemplatePropagationReport{Updated: []string{}, Failed: map[string]string{}}
+	if !propagate {
+		return report, nil, http.StatusOK
+	}
+	ctx, _ = util.GetTimeoutContext()
+	ids, err := this.db.ListInstanceIdsByTemplate(ctx, template.Id)
+	if err != nil {
+		return report, err, http.StatusInternalServerError
+	}
+	for _, id := range ids {
+		err = this.propagateTemplate(template, id, userId, token)
+		if err != nil {
+			report.Failed[id] = err.Error()
+			continue
+		}
+		report.Updated = append(report.Updated, id)
+	}
+	return report, nil, http.StatusOK
+}
+
+// propagateTemplate applies the template to a linked instance through the regular update
+func (this *Controller) propagateTemplate(template model.Template, id string, userId string, token string) error {
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return errors.New("not found")
+	}
+	if err != nil {
+		return err
+	}
+	if instance.TemplateId != template.Id {
+		return nil // unlinked in the meantime
+	}
+	applyTemplate(&instance, template)
+	err, _ = this.SetInstance(instance, userId, token, []int64{instance.Revision}) // rejects changes made since the instance was loaded
+	return err
+}
+
+// DeleteTemplate removes a template. Linked instances keep their settings and are unlinked.
+func (this *Controller) DeleteTemplate(token string, userId string, id string) (err error, code int) {
+	defer func() {
+		this.auditTemplate(model.AuditActionTemplateDelete, userId, id, nil, err)
+	}()
+	_, err, code = this.readTemplate(token, id, permv2.Administrate)
+	if err != nil {
+		return err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	ids, err := this.db.ListInstanceIdsByTemplate(ctx, id)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	for _, instanceId := range ids {
+		err = this.unlinkTemplate(instanceId, id, userId)
+		if err != nil {
+			return err, storeErrorCode(err)
+		}
+	}
+	ctx, _ = util.GetTimeoutContext()
+	err = this.db.RemoveTemplate(ctx, id)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	err, code = this.permv2.RemoveResource(token, Permv2TemplateTopic, id)
+	if err != nil && code != http.StatusNotFound {
+		return err, code
+	}
+	return nil, http.StatusNoContent
+}
+
+// unlinkTemplate removes the template reference of an instance, the deployment is not affected
+func (this *Controller) unlinkTemplate(instanceId string, templateId string, userId string) error {
+	defer this.instanceLocks.Lock(instanceId)()
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, instanceId)
+	if !exists {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if instance.TemplateId != templateId {
+		return nil
+	}
+	loaded := instance
+	instance.TemplateId = ""
+	return this.storeInstanceChange(&instance, userId, loaded)
+}
+
+// CreateInstanceFromTemplate creates an instance linked to the template. The shared settings of the template replace those of the given instance,
+// which only has to provide the instance specific fields like Name, FilterType, Filter and Topic.
+// The CustomMqttPassword of the template is only used if the caller may write the template, otherwise the one of the given instance is kept.
+func (this *Controller) CreateInstanceFromTemplate(token string, userId string, templateId string, instance model.Instance) (result model.Instance, err error, code int) {
+	template, err, code := this.readTemplate(token, templateId, permv2.Read)
+	if err != nil {
+		return result, err, code
+	}
+	canWrite, err, code := this.permv2.CheckPermission(token, Permv2TemplateTopic, templateId, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	if !canWrite {
+		template.CustomMqttPassword = instance.CustomMqttPassword // readers must not learn the password through the created instance
+	}
+	applyTemplate(&instance, template)
+	instance.TemplateId = template.Id
+	return this.createInstance(instance, userId, token, tokenRoles(token, userId), model.AuditActionCreate, "")
+}
+
+func applyTemplate(instance *model.Instance, template model.Template) {
+	instance.Offset = template.Offset
+	instance.Values = template.Values
+	instance.CustomMqttBroker = template.CustomMqttBroker
+	instance.CustomMqttUser = template.CustomMqttUser
+	instance.CustomMqttPassword = template.CustomMqttPassword
+	instance.CustomMqttBaseTopic = template.CustomMqttBaseTopic
+	instance.ImageVersion = template.ImageVersion
+}
+
+func (this *Controller) verifyTemplate(template model.Template) (err error, code int) {
+	if template.Name == "" {
+		return errors.New("missing name"), http.StatusBadRequest
+	}
+	if template.Offset == "" {
+		return errors.New("missing offset"), http.StatusBadRequest
+	}
+	if template.CustomMqttBroker == nil && (template.CustomMqttUser != nil || template.CustomMqttPassword != nil || template.CustomMqttBaseTopic != nil) {
+		return errors.New("must not set custom mqtt options with default broker"), http.StatusBadRequest
+	}
+	return this.verifyImageVersion(model.Instance{ImageVersion: template.ImageVersion})
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// deleted instances are checked at least this often, shorter retentions are checked once per retention
+const purgeInterval = time.Hour
+
+func (this *Controller) ListDeletedInstances(token string, limit int64, offset int64) (results []model.Instance, err error, errCode int) {
+	ids, err, errCode := this.permv2.ListAccessibleResourceIds(token, Permv2topic, permv2.ListOptions{}, permv2.Read)
+	if err != nil {
+		return nil, err, errCode
+	}
+	ctx, _ := util.GetTimeoutContext()
+	results, err = this.db.ListDeletedInstances(ctx, limit, offset, ids, nil)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return results, nil, http.StatusOK
+}
+
+// RestoreInstance redeploys a deleted instance with the consumer group of its removed workload, so it continues where it stopped.
+func (this *Controller) RestoreInstance(token string, userId string, id string) (err error, errCode int) {
+	defer func() {
+		this.audit(model.AuditActionRestore, userId, id, nil, err)
+	}()
+	ok, err, errCode := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Administrate)
+	if err != nil {
+		return err, errCode
+	}
+	if !ok {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := util.GetTimeoutContext()
+	instances, exists, err := this.db.GetDeletedInstances(ctx, []string{id})
+	if !exists {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	instance := instances[0]
+	loaded := instance
+	retention := this.deletedInstanceRetention()
+	if retention > 0 && time.Since(*instance.DeletedAt) > retention {
+		return errors.New("restore window expired"), http.StatusGone
+	}
+	unlockQuota, err := this.lockQuota(instance.UserId)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	defer unlockQuota()
+	err, errCode = this.checkQuota(tokenRoles(token, instance.UserId), instance.UserId, 1)
+	if err != nil {
+		return err, errCode
+	}
+	if instance.TemplateId != "" {
+		ctx, _ = util.GetTimeoutContext()
+		templates, err := this.db.ListTemplates(ctx, 1, 0, []string{instance.TemplateId})
+		if err != nil {
+			return err, http.StatusInternalServerError
+		}
+		if len(templates) == 0 {
+			instance.TemplateId = "" // removed while the instance was in the trash
+		}
+	}
+
+	if isExpired(instance, time.Now()) {
+		instance.ExpiresAt = nil // deleted on expiry, restoring it must not delete it again
+		instance.ExpiryWarnedAt = nil
+	}
+	instance.ScheduleState = scheduleState(instance.Schedule, time.Now())
+	err = this.restoreDeployment(&instance, this.getImage(instance), instance.KafkaGroupId)
+	if err != nil {
+		this.compensate(model.Compensation{Action: model.CompensationRemoveWorkload, InstanceId: instance.Id, ServiceId: instance.ServiceId})
+		return err, http.StatusInternalServerError
+	}
+	instance.DeletedAt = nil
+	err = this.storeInstanceChange(&instance, userId, loaded)
+	if err != nil {
+		this.compensate(model.Compensation{Action: model.CompensationRemoveWorkload, InstanceId: instance.Id, ServiceId: instance.ServiceId})
+		return err, storeErrorCode(err)
+	}
+	return nil, http.StatusNoContent
+}
+
+// PurgeInstance permanently removes a deleted instance before its retention period ends
+func (this *Controller) PurgeInstance(token string, userId string, id string) (err error, errCode int) {
+	defer func() {
+		this.audit(model.AuditActionPurge, userId, id, nil, err)
+	}()
+	ok, err, errCode := this.permv2.CheckPermission(token, Permv2topic, id, permv2.Administrate)
+	if err != nil {
+		return err, errCode
+	}
+	if !ok {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	return this.purgeInstances([]string{id}, userId, true)
+}
+
+// trashInstances moves all given instances or none of them to the trash. Their workloads are removed,
+// records, permissions and the deployed consumer groups are kept for restoring them.
+// Instances listed in expectedRevisions are only deleted if they still have one of the given revisions.
+// The deletion is stored as a new revision of every instance.
+func (this *Controller) trashInstances(ids []string, userId string, expectedRevisions map[string][]int64) (err error, errCode int) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids) // consistent lock order
+	ids = slices.Compact(ids)
+	for _, id := range ids {
+		defer this.instanceLocks.Lock(id)()
+	}
+	ctx, _ := getTimeoutContext()
+	instances, exists, err := this.db.GetInstances(ctx, ids)
+	if !exists {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	for _, instance := range instances {
+		if expected, ok := expectedRevisions[instance.Id]; ok && !slices.Contains(expected, instance.Revision) {
+			return errRevisionConflict, http.StatusPreconditionFailed
+		}
+	}
+
+	now := time.Now()
+	loaded := slices.Clone(instances)
+	for i := range instances {
+		_, instances[i].KafkaGroupId = this.deployedVersion(instances[i])
+		instances[i].Revision = loaded[i].Revision + 1
+	}
+	stopped, err := this.stopWorkloads(instances)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+
+	removals := []model.Compensation{}
+	for i := range instances {
+		instances[i].DeletedAt = &now
+		removal := model.Compensation{Action: model.CompensationRemoveWorkload, InstanceId: instances[i].Id, ServiceId: instances[i].ServiceId}
+		err = this.newCompensation(&removal)
+		if err != nil {
+			this.restartWorkloads(stopped)
+			return err, http.StatusInternalServerError
+		}
+		removals = append(removals, removal)
+	}
+	err = this.storeTrashedInstances(instances, loaded, userId, removals)
+	if err != nil {
+		this.restartWorkloads(stopped)
+		return err, storeErrorCode(err)
+	}
+
+	for _, removal := range removals {
+		err = this.retryCompensation(removal)
+		if err != nil {
+			log.Println("ERROR: unable to remove workload of deleted instance", removal.InstanceId+", will retry:", err)
+		}
+	}
+	return nil, http.StatusNoContent
+}
+
+// storeTrashedInstances stores the trashed instances as new revisions of their loaded versions together with the removals of their workloads
+func (this *Controller) storeTrashedInstances(instances []model.Instance, loaded []model.Instance, userId string, removals []model.Compensation) (err error) {
+	ctx, _ := getTimeoutContext()
+	ctx, finish, err := this.db.Transaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		finishErr := finish(err == nil)
+		if err == nil {
+			err = finishErr
+		}
+		if err == nil {
+			this.notifyLifecyclePublisher()
+		}
+	}()
+	for _, removal := range removals {
+		err = this.db.SetCompensation(ctx, removal)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range instances {
+		err = this.writeInstance(ctx, instances[i], userId, &loaded[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartPurgeLoop periodically removes deleted instances whose deleted_instance_retention has passed until the controller context is done.
+// The loop is disabled if deleted_instance_retention is empty or not positive.
+func (this *Controller) StartPurgeLoop() error {
+	if this.config.DeletedInstanceRetention == "" {
+		return nil
+	}
+	retention, err := time.ParseDuration(this.config.DeletedInstanceRetention)
+	if err != nil {
+		return err
+	}
+	if retention <= 0 {
+		return nil
+	}
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(min(retention, purgeInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+				this.purgeExpiredInstances(retention)
+			}
+		}
+	}()
+	return nil
+}
+
+func (this *Controller) purgeExpiredInstances(retention time.Duration) {
+	deletedBefore := time.Now().Add(-retention)
+	ctx, _ := util.GetTimeoutContext()
+	// purged instances are removed while iterating, so a single snapshot is used instead of paging
+	instances, err := this.db.ListDeletedInstances(ctx, 0, 0, nil, &deletedBefore)
+	if err != nil {
+		log.Println("ERROR: unable to list expired deleted instances:", err)
+		return
+	}
+	for _, instance := range instances {
+		if this.ctx.Err() != nil {
+			return
+		}
+		err, _ = this.purgeInstances([]string{instance.Id}, "", true)
+		this.audit(model.AuditActionPurge, "", instance.Id, nil, err)
+		if err != nil {
+			log.Println("ERROR: unable to purge deleted instance", instance.Id, err)
+			continue
+		}
+		log.Println("purged deleted instance", instance.Id)
+	}
+}
+
+// deletedInstanceRetention returns the restore window of deleted instances, 0 if it is unlimited
+func (this *Controller) deletedInstanceRetention() time.Duration {
+	retention, err := time.ParseDuration(this.config.DeletedInstanceRetention)
+	if err != nil || retention < 0 {
+		return 0
+	}
+	return retention
+}
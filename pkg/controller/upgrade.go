@@ -0,0 +1,314 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+)
+
+// the replica running an upgrade holds this lock, so only one upgrade runs at a time
+const upgradeLockKey = "upgrade"
+
+// StartUpgrade redeploys every instance with the configured transfer image in batches of options.Concurrency instances.
+// Instances with a pinned image version are redeployed with the pinned version.
+// The upgrade runs in the background of this replica, its progress is stored and available on every replica with GetUpgradeProgress.
+func (this *Controller) StartUpgrade(token string, userId string, options model.UpgradeOptions) (progress model.UpgradeProgress, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return progress, err, code
+	}
+	defer func() {
+		this.audit(model.AuditActionUpgrade, userId, "", nil, err)
+	}()
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.MaxFailures <= 0 {
+		options.MaxFailures = 1
+	}
+	var pause time.Duration
+	if options.BatchPause != "" {
+		pause, err = time.ParseDuration(options.BatchPause)
+		if err != nil {
+			return progress, err, http.StatusBadRequest
+		}
+	}
+
+	this.upgradeMux.Lock()
+	defer this.upgradeMux.Unlock()
+	if this.upgradeCancel != nil {
+		// running or still releasing the lock, which is shared by all upgrades of this replica
+		return this.copyUpgradeProgress(), errors.New("upgrade already running"), http.StatusConflict
+	}
+	ctx, _ := util.GetTimeoutContext()
+	acquired, err := this.db.AcquireLock(ctx, upgradeLockKey, this.lockOwner, time.Now().Add(lockLease))
+	if err != nil {
+		return progress, err, http.StatusInternalServerError
+	}
+	if !acquired {
+		progress, _, _ = this.db.GetUpgradeProgress(ctx)
+		return progress, errors.New("upgrade already running on another replica"), http.StatusConflict
+	}
+	ids, err := this.listInstanceIds()
+	if err == nil {
+		progress = model.UpgradeProgress{
+			Running:   true,
+			Image:     this.config.TransferImage,
+			Options:   options,
+			StartedAt: time.Now(),
+			Total:     len(ids),
+			Failed:    map[string]string{},
+			UpdatedAt: time.Now(),
+		}
+		err = this.db.SetUpgradeProgress(ctx, progress)
+	}
+	if err != nil {
+		releaseErr := this.db.ReleaseLock(ctx, upgradeLockKey, this.lockOwner)
+		if releaseErr != nil {
+			log.Println("ERROR: unable to release upgrade lock", releaseErr)
+		}
+		return model.UpgradeProgress{}, err, http.StatusInternalServerError
+	}
+	this.upgrade = &progress
+	upgradeCtx, cancel := context.WithCancel(this.ctx)
+	this.upgradeCancel = cancel
+	release := this.holdLock(upgradeLockKey, func(locked bool) {
+		if !locked {
+			cancel()
+			return
+		}
+		this.watchUpgrade(cancel)
+	})
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		this.runUpgrade(upgradeCtx, ids, options, pause)
+		cancel()
+		release()
+		this.upgradeMux.Lock()
+		this.upgradeCancel = nil
+		this.upgradeMux.Unlock()
+	}()
+	return this.copyUpgradeProgress(), nil, http.StatusAccepted
+}
+
+// GetUpgradeProgress returns the stored progress, independent of the replica running the upgrade
+func (this *Controller) GetUpgradeProgress(token string) (progress model.UpgradeProgress, err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return progress, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	progress, exists, err := this.db.GetUpgradeProgress(ctx)
+	if err != nil {
+		return progress, err, http.StatusInternalServerError
+	}
+	if !exists {
+		return progress, errors.New("no upgrade started"), http.StatusNotFound
+	}
+	if progress.Running && time.Since(progress.UpdatedAt) > lockLease {
+		// the replica running the upgrade stopped without finishing it
+		progress.Running = false
+		progress.Error = "interrupted"
+	}
+	return progress, nil, http.StatusOK
+}
+
+// CancelUpgrade stops a running upgrade after the current batch.
+// An upgrade running on another replica notices the request within a quarter of the lock lease.
+func (this *Controller) CancelUpgrade(token string) (err error, code int) {
+	err, code = checkAdmin(token)
+	if err != nil {
+		return err, code
+	}
+	this.upgradeMux.Lock()
+	defer this.upgradeMux.Unlock()
+	ctx, _ := util.GetTimeoutContext()
+	running, err := this.db.RequestUpgradeCancel(ctx)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if this.upgrade != nil && this.upgrade.Running {
+		this.upgrade.CancelRequested = true
+		this.upgradeCancel()
+		return nil, http.StatusNoContent
+	}
+	if !running {
+		return errors.New("no upgrade running"), http.StatusNotFound
+	}
+	return nil, http.StatusNoContent
+}
+
+// copyUpgradeProgress expects upgradeMux to be locked
+func (this *Controller) copyUpgradeProgress() model.UpgradeProgress {
+	progress := *this.upgrade
+	progress.Failed = maps.Clone(this.upgrade.Failed)
+	return progress
+}
+
+// storeUpgradeProgress expects upgradeMux to be locked
+func (this *Controller) storeUpgradeProgress() {
+	this.upgrade.UpdatedAt = time.Now()
+	ctx, _ := util.GetTimeoutContext()
+	err := this.db.UpdateUpgradeProgress(ctx, *this.upgrade)
+	if err != nil {
+		log.Println("ERROR: unable to store upgrade progress", err)
+	}
+}
+
+// watchUpgrade is called on every extension of the upgrade lock.
+// It refreshes the stored progress, so other replicas see the upgrade is alive, and cancels it if another replica requested it.
+func (this *Controller) watchUpgrade(cancel context.CancelFunc) {
+	ctx, _ := util.GetTimeoutContext()
+	stored, exists, err := this.db.GetUpgradeProgress(ctx)
+	if err != nil {
+		log.Println("ERROR: unable to load upgrade progress", err)
+	} else if exists && stored.CancelRequested {
+		cancel()
+	}
+	this.upgradeMux.Lock()
+	defer this.upgradeMux.Unlock()
+	this.storeUpgradeProgress()
+}
+
+func (this *Controller) runUpgrade(ctx context.Context, ids []string, options model.UpgradeOptions, pause time.Duration) {
+	log.Println("Starting upgrade of", len(ids), "instances to", this.config.TransferImage)
+	finish := func(reason string) {
+		this.upgradeMux.Lock()
+		defer this.upgradeMux.Unlock()
+		now := time.Now()
+		this.upgrade.Running = false
+		this.upgrade.FinishedAt = &now
+		this.upgrade.Error = reason
+		this.storeUpgradeProgress()
+		log.Println("Finished upgrade, upgraded", this.upgrade.Upgraded, "of", this.upgrade.Total, "instances", reason)
+	}
+	for start := 0; start < len(ids); start += options.Concurrency {
+		if start > 0 && pause > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(pause):
+			}
+		}
+		if ctx.Err() != nil {
+			finish("cancelled")
+			return
+		}
+		end := min(start+options.Concurrency, len(ids))
+		wg := sync.WaitGroup{}
+		for _, id := range ids[start:end] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := this.upgradeInstance(id)
+				this.upgradeMux.Lock()
+				defer this.upgradeMux.Unlock()
+				if err != nil {
+					log.Println("ERROR: unable to upgrade", id, err)
+					this.upgrade.Failed[id] = err.Error()
+				} else {
+					this.upgrade.Upgraded++
+				}
+				this.storeUpgradeProgress()
+			}()
+		}
+		wg.Wait()
+		this.upgradeMux.Lock()
+		failures := len(this.upgrade.Failed)
+		this.upgradeMux.Unlock()
+		if failures >= options.MaxFailures {
+			finish("stopped after " + strconv.Itoa(failures) + " failures")
+			return
+		}
+	}
+	finish("")
+}
+
+// upgradeInstance redeploys an instance with its current configuration and the configured image.
+// Image tags are not compared, because a moving tag (e.g. prod) may point to a new image.
+func (this *Controller) upgradeInstance(id string) error {
+	defer this.instanceLocks.Lock(id)()
+	ctx, _ := util.GetTimeoutContext()
+	instance, exists, err := this.db.GetInstance(ctx, id)
+	if !exists {
+		return nil // removed since upgrade start
+	}
+	if err != nil {
+		return err
+	}
+	env, err, _ := this.getEnv(&instance, "", instance.UserId, false)
+	if err != nil {
+		return err
+	}
+	deployed, exists, err := this.deploymentClient.GetContainerConfig(instance.ServiceId)
+	if err != nil {
+		return err
+	}
+	loaded := instance
+	previousServiceId := instance.ServiceId
+	if exists {
+		keepDeployedConsumerGroup(&instance, deployed, env)
+		err = this.redeploy(&instance, this.getImage(instance), env)
+	} else {
+		var serviceId string
+		serviceId, err = this.deploymentClient.CreateContainer(containerName(instance), this.getImage(instance), instance.UserId, env, true)
+		if err == nil {
+			instance.ServiceId = serviceId
+			if !shouldRun(instance) {
+				err = this.deploymentClient.StopContainer(instance.ServiceId)
+			}
+		}
+	}
+	if err != nil && instance.ServiceId == previousServiceId {
+		return err
+	}
+	// the workload has been replaced, its new id must be stored even if stopping a paused or unscheduled instance failed
+	storeErr := this.storeInstanceChange(&instance, "", loaded)
+	if errors.Is(storeErr, errRevisionConflict) {
+		this.redeployStored(instance, instance.KafkaGroupId)
+	}
+	return errors.Join(err, storeErr)
+}
+
+func (this *Controller) listInstanceIds() (ids []string, err error) {
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ := util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "id", true, "", true, nil, nil)
+		if err != nil {
+			return ids, err
+		}
+		offset += int64(len(instances))
+		for _, instance := range instances {
+			ids = append(ids, instance.Id)
+		}
+		if len(instances) < int(batchSize) {
+			return ids, nil // done
+		}
+	}
+}
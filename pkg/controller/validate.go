@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// ValidateCreateInstance runs the checks of CreateInstance and returns the configuration it would deploy.
+// Validation errors are part of the result, err is only set if the validation itself failed.
+func (this *Controller) ValidateCreateInstance(instance model.Instance, userId string, token string) (result model.ValidationResult, err error, code int) {
+	instance, env, err, code := this.prepareCreate(instance, userId, token, "")
+	return this.validationResult(instance, env, err, code)
+}
+
+// ValidateSetInstance runs the checks of SetInstance and returns the configuration it would deploy.
+// Validation errors are part of the result, err is only set if the validation itself failed or access is denied.
+func (this *Controller) ValidateSetInstance(instance model.Instance, userId string, token string) (result model.ValidationResult, err error, code int) {
+	ok, err, code := this.permv2.CheckPermission(token, Permv2topic, instance.Id, permv2.Write)
+	if err != nil {
+		return result, err, code
+	}
+	if !ok {
+		return result, errors.New("not found"), http.StatusNotFound
+	}
+	existing, instance, env, err, code := this.prepareUpdate(instance, userId, token)
+	if err != nil && existing.Id == "" {
+		return result, err, code // stored instance could not be loaded
+	}
+	return this.validationResult(instance, env, err, code)
+}
+
+func (this *Controller) validationResult(instance model.Instance, env map[string]string, err error, code int) (result model.ValidationResult, _ error, _ int) {
+	if err != nil && code >= http.StatusInternalServerError {
+		return result, err, code
+	}
+	if err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			for _, e := range joined.Unwrap() {
+				result.Errors = append(result.Errors, e.Error())
+			}
+		} else {
+			result.Errors = []string{err.Error()}
+		}
+		return result, nil, http.StatusOK
+	}
+	result.Valid = true
+	result.Image = this.getImage(instance)
+	result.Env = map[string]string{}
+	for key, value := range env {
+		result.Env[key] = maskEnvValue(key, value)
+	}
+	return result, nil, http.StatusOK
+}
@@ -0,0 +1,550 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/webhook"
+	permv2client "github.com/SENERGY-Platform/permissions-v2/pkg/client"
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/hashicorp/go-uuid"
+)
+
+// pending deliveries are polled this often, deliveries queued by this replica are attempted immediately
+const webhookDeliveryPollInterval = 5 * time.Second
+
+// a claimed delivery is not attempted by other replicas for this duration
+const webhookDeliveryLease = time.Minute
+
+// the delay before the first retry, doubled for every further attempt up to webhookMaxRetryDelay
+const webhookRetryDelay = 30 * time.Second
+
+const webhookMaxRetryDelay = time.Hour
+
+// a delivery failing this often is given up
+const webhookMaxAttempts = 8
+
+// finished deliveries are removed at least this often if webhook_delivery_retention is set
+const webhookPurgeInterval = time.Hour
+
+// states which can be subscribed to, unknown states are reported if the backend is unavailable and are never notified
+var webhookStates = []string{
+	model.DeploymentStateRunning,
+	model.DeploymentStateRestarting,
+	model.DeploymentStateCrashLoop,
+	model.DeploymentStateStopped,
+	model.DeploymentStateFailed,
+	model.DeploymentStateMissing,
+}
+
+// ListWebhooks returns the webhooks of the user without their secrets
+func (this *Controller) ListWebhooks(token string, userId string) (result []model.Webhook, err error, code int) {
+	ctx, _ := util.GetTimeoutContext()
+	result, err = this.db.ListWebhooks(ctx, userId)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	for i := range result {
+		result[i].Secret = ""
+	}
+	return result, nil, http.StatusOK
+}
+
+// CreateWebhook registers a webhook of the user. A secret is generated if none is given, it is only returned by this call.
+func (this *Controller) CreateWebhook(token string, userId string, hook model.Webhook) (result model.Webhook, err error, code int) {
+	if hook.Id != "" {
+		return result, errors.New("explicit setting of id not allowed"), http.StatusBadRequest
+	}
+	err = verifyWebhook(hook)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+	if hook.InstanceId != "" {
+		err, code = this.checkInstancePermission(token, hook.InstanceId, permv2.Read)
+		if err != nil {
+			return result, err, code
+		}
+	}
+	hook.Id, err = uuid.GenerateUUID()
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if hook.Secret == "" {
+		hook.Secret, err = newWebhookSecret()
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+	}
+	hook.UserId = userId
+	hook.CreatedAt = time.Now()
+	parsed, err := jwt.Parse(token)
+	if err == nil && parsed.GetUserId() == userId {
+		hook.Roles = parsed.GetRoles()
+		hook.Groups = parsed.GetGroups()
+	}
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.AddWebhook(ctx, hook)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return hook, nil, http.StatusOK
+}
+
+// DeleteWebhook removes a webhook of the user and its delivery log
+func (this *Controller) DeleteWebhook(token string, userId string, id string) (err error, code int) {
+	_, err, code = this.getOwnWebhook(userId, id)
+	if err != nil {
+		return err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	exists, err := this.db.RemoveWebhook(ctx, id)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if !exists {
+		return errors.New("not found"), http.StatusNotFound
+	}
+	return nil, http.StatusNoContent
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook of the user, newest first
+func (this *Controller) ListWebhookDeliveries(token string, userId string, id string, limit int64, offset int64) (result []model.WebhookDelivery, err error, code int) {
+	_, err, code = this.getOwnWebhook(userId, id)
+	if err != nil {
+		return nil, err, code
+	}
+	ctx, _ := util.GetTimeoutContext()
+	result, err = this.db.ListWebhookDeliveries(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// TestWebhook posts a test notification to a webhook of the user once, without retries.
+// The logged delivery is returned, a failed delivery is no error of the call.
+func (this *Controller) TestWebhook(token string, userId string, id string) (result model.WebhookDelivery, err error, code int) {
+	hook, err, code := this.getOwnWebhook(userId, id)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = newWebhookDelivery(hook, model.WebhookNotification{Event: model.WebhookEventTest, InstanceId: hook.InstanceId, UserId: hook.UserId})
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	sendErr := this.sendWebhook(hook, &result)
+	now := time.Now()
+	result.Status = model.WebhookDeliverySucceeded
+	if sendErr != nil {
+		result.Status = model.WebhookDeliveryFailed
+	}
+	result.NextAttemptAt = nil
+	result.FinishedAt = &now
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.AddWebhookDelivery(ctx, result)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// getOwnWebhook returns the webhook if it belongs to the user
+func (this *Controller) getOwnWebhook(userId string, id string) (result model.Webhook, err error, code int) {
+	ctx, _ := util.GetTimeoutContext()
+	result, exists, err := this.db.GetWebhook(ctx, id)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if !exists || result.UserId != userId {
+		return model.Webhook{}, errors.New("not found"), http.StatusNotFound
+	}
+	return result, nil, http.StatusOK
+}
+
+// StartWebhooks checks the deployment states of instances with webhooks every webhook_check_interval and delivers
+// notifications of changed states until the controller context is done. Finished deliveries are removed after webhook_delivery_retention.
+// Webhooks are disabled if webhook_check_interval is empty or not positive.
+func (this *Controller) StartWebhooks() error {
+	if this.config.WebhookCheckInterval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(this.config.WebhookCheckInterval)
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return nil
+	}
+	var retention time.Duration
+	if this.config.WebhookDeliveryRetention != "" {
+		retention, err = time.ParseDuration(this.config.WebhookDeliveryRetention)
+		if err != nil {
+			return err
+		}
+	}
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+				err := this.checkInstanceStates()
+				if err != nil {
+					log.Println("ERROR: checking instance states for webhooks aborted:", err)
+				}
+			}
+		}
+	}()
+	this.wg.Add(1)
+	go func() {
+		defer this.wg.Done()
+		ticker := time.NewTicker(webhookDeliveryPollInterval)
+		defer ticker.Stop()
+		for {
+			this.deliverWebhooks()
+			select {
+			case <-this.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-this.webhookQueued:
+			}
+		}
+	}()
+	if retention > 0 {
+		this.wg.Add(1)
+		go func() {
+			defer this.wg.Done()
+			ticker := time.NewTicker(min(retention, webhookPurgeInterval))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-this.ctx.Done():
+					return
+				case <-ticker.C:
+					ctx, _ := util.GetTimeoutContext()
+					err := this.db.RemoveFinishedWebhookDeliveries(ctx, time.Now().Add(-retention))
+					if err != nil {
+						log.Println("ERROR: unable to remove finished webhook deliveries:", err)
+					}
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// checkInstanceStates queues notifications for instances whose deployment state changed since the last check.
+// The first observed state of an instance is stored without notification.
+func (this *Controller) checkInstanceStates() error {
+	ctx, _ := util.GetTimeoutContext()
+	hooks, err := this.db.ListWebhooks(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	userHooks := map[string][]model.Webhook{}
+	instanceHooks := map[string][]model.Webhook{}
+	for _, hook := range hooks {
+		if hook.InstanceId == "" {
+			userHooks[hook.UserId] = append(userHooks[hook.UserId], hook)
+		} else {
+			instanceHooks[hook.InstanceId] = append(instanceHooks[hook.InstanceId], hook)
+		}
+	}
+	var offset int64 = 0
+	var batchSize int64 = 100
+	for {
+		ctx, _ = util.GetTimeoutContext()
+		instances, err := this.db.ListInstances(ctx, batchSize, offset, "id", true, "", true, nil, nil)
+		if err != nil {
+			return err
+		}
+		offset += int64(len(instances))
+		watched := slices.DeleteFunc(slices.Clone(instances), func(instance model.Instance) bool {
+			return len(userHooks[instance.UserId]) == 0 && len(instanceHooks[instance.Id]) == 0
+		})
+		if len(watched) > 0 {
+			err = this.checkInstanceBatch(watched, func(instance model.Instance) []model.Webhook {
+				return append(slices.Clone(userHooks[instance.UserId]), instanceHooks[instance.Id]...)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if this.ctx.Err() != nil {
+			return this.ctx.Err()
+		}
+		if len(instances) < int(batchSize) {
+			return nil // done
+		}
+	}
+}
+
+func (this *Controller) checkInstanceBatch(instances []model.Instance, hooksOf func(instance model.Instance) []model.Webhook) error {
+	ids := []string{}
+	for _, instance := range instances {
+		ids = append(ids, instance.Id)
+	}
+	ctx, _ := util.GetTimeoutContext()
+	stored, err := this.db.GetInstanceStates(ctx, ids)
+	if err != nil {
+		return err
+	}
+	previous := map[string]string{}
+	for _, state := range stored {
+		previous[state.InstanceId] = state.State
+	}
+	this.addDeploymentStatus(instances)
+	for _, instance := range instances {
+		state := instance.Status.State
+		if state == model.DeploymentStateUnknown || state == previous[instance.Id] {
+			continue
+		}
+		ctx, _ = util.GetTimeoutContext()
+		changed, err := this.db.SetInstanceState(ctx, model.InstanceState{InstanceId: instance.Id, State: state, ChangedAt: time.Now()}, previous[instance.Id])
+		if err != nil {
+			log.Println("ERROR: unable to store deployment state of", instance.Id, err)
+			continue
+		}
+		if !changed || previous[instance.Id] == "" {
+			continue // notified by another replica or first observation
+		}
+		for _, hook := range this.permittedWebhooks(instance.Id, hooksOf(instance)) {
+			if len(hook.States) > 0 && !slices.Contains(hook.States, state) {
+				continue
+			}
+			err = this.queueWebhookDelivery(hook, model.WebhookNotification{
+				Event:        model.WebhookEventStateChanged,
+				InstanceId:   instance.Id,
+				InstanceName: instance.Name,
+				UserId:       instance.UserId,
+				From:         previous[instance.Id],
+				To:           state,
+				Message:      instance.Status.Message,
+			})
+			if err != nil {
+				log.Println("ERROR: unable to queue webhook delivery for", instance.Id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// permittedWebhooks drops the webhooks whose owners can no longer read the instance.
+// Webhooks of the instance itself are removed in that case, webhooks of all instances of a user stay for the other instances.
+func (this *Controller) permittedWebhooks(instanceId string, hooks []model.Webhook) (result []model.Webhook) {
+	if len(hooks) == 0 {
+		return hooks
+	}
+	resource, err, _ := this.permv2.GetResource(permv2client.InternalAdminToken, Permv2topic, instanceId)
+	if err != nil {
+		log.Println("ERROR: unable to check webhook permissions for", instanceId, err)
+		return nil
+	}
+	for _, hook := range hooks {
+		if canReadResource(resource.ResourcePermissions, hook.UserId, hook.Roles, hook.Groups) {
+			result = append(result, hook)
+			continue
+		}
+		if hook.InstanceId == "" {
+			continue
+		}
+		log.Println("WARNING: removing webhook", hook.Id, "of", hook.UserId, "who lost read access to", instanceId)
+		ctx, _ := util.GetTimeoutContext()
+		_, err = this.db.RemoveWebhook(ctx, hook.Id)
+		if err != nil {
+			log.Println("ERROR: unable to remove webhook", hook.Id, err)
+		}
+	}
+	return result
+}
+
+func canReadResource(permissions permv2.ResourcePermissions, userId string, roles []string, groups []string) bool {
+	if permissions.UserPermissions[userId].Read {
+		return true
+	}
+	for _, role := range roles {
+		if permissions.RolePermissions[role].Read {
+			return true
+		}
+	}
+	for _, group := range groups {
+		if permissions.GroupPermissions[group].Read {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *Controller) queueWebhookDelivery(hook model.Webhook, notification model.WebhookNotification) error {
+	delivery, err := newWebhookDelivery(hook, notification)
+	if err != nil {
+		return err
+	}
+	ctx, _ := util.GetTimeoutContext()
+	err = this.db.AddWebhookDelivery(ctx, delivery)
+	if err != nil {
+		return err
+	}
+	select {
+	case this.webhookQueued <- struct{}{}:
+	default: // the delivery worker is already notified
+	}
+	return nil
+}
+
+// deliverWebhooks attempts all due deliveries until none is left or the controller context is done
+func (this *Controller) deliverWebhooks() {
+	for this.ctx.Err() == nil {
+		now := time.Now()
+		ctx, _ := util.GetTimeoutContext()
+		delivery, found, err := this.db.ClaimWebhookDelivery(ctx, now, now.Add(webhookDeliveryLease))
+		if err != nil {
+			log.Println("ERROR: unable to claim webhook delivery:", err)
+			return
+		}
+		if !found {
+			return
+		}
+		err = this.attemptWebhookDelivery(delivery)
+		if err != nil {
+			log.Println("ERROR: unable to update webhook delivery", delivery.Id, err)
+		}
+	}
+}
+
+// attemptWebhookDelivery sends the notification once and schedules the next attempt with exponential backoff if it fails
+func (this *Controller) attemptWebhookDelivery(delivery model.WebhookDelivery) error {
+	ctx, _ := util.GetTimeoutContext()
+	hook, exists, err := this.db.GetWebhook(ctx, delivery.WebhookId)
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	if exists {
+		sendErr = this.sendWebhook(hook, &delivery)
+	} else {
+		sendErr = errors.New("webhook removed")
+		delivery.Attempts = webhookMaxAttempts
+		delivery.LastError = sendErr.Error()
+	}
+	now := time.Now()
+	switch {
+	case sendErr == nil:
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.FinishedAt = &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.FinishedAt = &now
+	default:
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+	ctx, _ = util.GetTimeoutContext()
+	return this.db.SetWebhookDelivery(ctx, delivery)
+}
+
+// sendWebhook posts the notification of the delivery and records the attempt in it
+func (this *Controller) sendWebhook(hook model.Webhook, delivery *model.WebhookDelivery) error {
+	code, err := webhook.Send(hook.Url, hook.Secret, delivery.Notification)
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.LastError = ""
+	if err != nil {
+		delivery.LastError = err.Error()
+	}
+	return err
+}
+
+func newWebhookDelivery(hook model.Webhook, notification model.WebhookNotification) (delivery model.WebhookDelivery, err error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return delivery, err
+	}
+	now := time.Now()
+	notification.Id = id
+	notification.Timestamp = now
+	return model.WebhookDelivery{
+		Id:            id,
+		WebhookId:     hook.Id,
+		UserId:        hook.UserId,
+		Notification:  notification,
+		Status:        model.WebhookDeliveryPending,
+		CreatedAt:     now,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+// webhookBackoff returns the delay after the given number of failed attempts
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryDelay
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxRetryDelay)
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func verifyWebhook(hook model.Webhook) error {
+	parsed, err := url.Parse(hook.Url)
+	if err != nil {
+		return errors.New("invalid webhook url: " + err.Error())
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("invalid webhook url, expected an absolute http or https url")
+	}
+	// resolved hosts are checked on every delivery, see webhook.Send
+	host := strings.ToLower(parsed.Hostname())
+	if ip, err := netip.ParseAddr(host); (err == nil && !webhook.IsPublicAddress(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("invalid webhook url, internal addresses are not allowed")
+	}
+	for _, state := range hook.States {
+		if !slices.Contains(webhookStates, state) {
+			return errors.New("unknown webhook state " + state)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, webhookRetryDelay},
+		{1, webhookRetryDelay},
+		{2, 2 * webhookRetryDelay},
+		{3, 4 * webhookRetryDelay},
+		{7, 64 * webhookRetryDelay},
+		{8, webhookMaxRetryDelay},
+		{webhookMaxAttempts, webhookMaxRetryDelay},
+		{100, webhookMaxRetryDelay},
+		{1 << 30, webhookMaxRetryDelay},
+	}
+	for _, test := range tests {
+		actual := webhookBackoff(test.attempts)
+		if actual != test.expected {
+			t.Errorf("webhookBackoff(%v): expected %v, got %v", test.attempts, test.expected, actual)
+		}
+	}
+	for attempts := 1; attempts < 20; attempts++ {
+		if webhookBackoff(attempts) < webhookBackoff(attempts-1) {
+			t.Errorf("webhookBackoff(%v) is shorter than the delay before", attempts)
+		}
+	}
+}
+
+func TestCanReadResource(t *testing.T) {
+	permissions := permv2.ResourcePermissions{
+		UserPermissions:  map[string]permv2.PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}, "writer": {Write: true}},
+		RolePermissions:  map[string]permv2.PermissionsMap{"admin": {Read: true}, "guest": {}},
+		GroupPermissions: map[string]permv2.PermissionsMap{"team": {Read: true}},
+	}
+	tests := []struct {
+		name     string
+		userId   string
+		roles    []string
+		groups   []string
+		expected bool
+	}{
+		{"user permission", "owner", nil, nil, true},
+		{"user without read", "writer", nil, nil, false},
+		{"unknown user", "other", []string{"user"}, []string{"other-team"}, false},
+		{"role permission", "other", []string{"user", "admin"}, nil, true},
+		{"role without read", "other", []string{"guest"}, nil, false},
+		{"group permission", "other", nil, []string{"team"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := canReadResource(permissions, test.userId, test.roles, test.groups)
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
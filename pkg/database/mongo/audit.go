@@ -0,0 +1,116 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var auditTimestampKey string
+var auditUserIdKey string
+var auditInstanceIdKey string
+var auditActionKey string
+
+func init() {
+	var err error
+	auditTimestampKey, err = getBsonFieldName(model.AuditEvent{}, "Timestamp")
+	if err != nil {
+		log.Fatal(err)
+	}
+	auditUserIdKey, err = getBsonFieldName(model.AuditEvent{}, "UserId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	auditInstanceIdKey, err = getBsonFieldName(model.AuditEvent{}, "InstanceId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	auditActionKey, err = getBsonFieldName(model.AuditEvent{}, "Action")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.auditCollection()
+		err = db.ensureIndex(collection, "auditTimestampIndex", auditTimestampKey, false, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "auditInstanceIdTimestampIndex", false, false, auditInstanceIdKey, auditTimestampKey)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) auditCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoAuditCollection)
+}
+
+func (this *Mongo) AddAuditEvent(ctx context.Context, event model.AuditEvent) error {
+	_, err := this.auditCollection().InsertOne(ctx, event)
+	return err
+}
+
+// ListAuditEvents returns the matching events, newest first
+func (this *Mongo) ListAuditEvents(ctx context.Context, query model.AuditQuery) (result []model.AuditEvent, err error) {
+	opt := options.Find()
+	opt.SetLimit(query.Limit)
+	opt.SetSkip(query.Offset)
+	opt.SetSort(bson.D{{Key: auditTimestampKey, Value: -1}})
+	filter := bson.M{}
+	timeRange := bson.M{}
+	if query.From != nil {
+		timeRange["$gte"] = *query.From
+	}
+	if query.To != nil {
+		timeRange["$lt"] = *query.To
+	}
+	if len(timeRange) > 0 {
+		filter[auditTimestampKey] = timeRange
+	}
+	if query.UserId != "" {
+		filter[auditUserIdKey] = query.UserId
+	}
+	if query.InstanceId != "" {
+		filter[auditInstanceIdKey] = query.InstanceId
+	}
+	if query.Action != "" {
+		filter[auditActionKey] = query.Action
+	}
+	cursor, err := this.auditCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.AuditEvent{}
+	for cursor.Next(context.Background()) {
+		event := model.AuditEvent{}
+		err = cursor.Decode(&event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, cursor.Err()
+}
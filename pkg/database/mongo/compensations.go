@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var compensationIdKey string
+var compensationCreatedAtKey string
+
+func init() {
+	var err error
+	compensationIdKey, err = getBsonFieldName(model.Compensation{}, "Id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	compensationCreatedAtKey, err = getBsonFieldName(model.Compensation{}, "CreatedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.compensationCollection()
+		err = db.ensureIndex(collection, "compensationIdIndex", compensationIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) compensationCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoCompensationCollection)
+}
+
+func (this *Mongo) ListCompensations(ctx context.Context, limit int64, offset int64) (result []model.Compensation, err error) {
+	opt := options.Find()
+	opt.SetLimit(limit)
+	opt.SetSkip(offset)
+	opt.SetSort(bson.D{{Key: compensationCreatedAtKey, Value: 1}})
+	cursor, err := this.compensationCollection().Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.Compensation{}
+	for cursor.Next(context.Background()) {
+		compensation := model.Compensation{}
+		err = cursor.Decode(&compensation)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, compensation)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) SetCompensation(ctx context.Context, compensation model.Compensation) error {
+	_, err := this.compensationCollection().ReplaceOne(ctx, bson.M{compensationIdKey: compensation.Id}, compensation, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (this *Mongo) RemoveCompensation(ctx context.Context, id string) error {
+	_, err := this.compensationCollection().DeleteOne(ctx, bson.M{compensationIdKey: id})
+	return err
+}
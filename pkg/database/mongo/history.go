@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var revisionInstanceIdKey string
+var revisionKey string
+
+func init() {
+	var err error
+	revisionInstanceIdKey, err = getBsonFieldName(model.InstanceRevision{}, "InstanceId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	revisionKey, err = getBsonFieldName(model.InstanceRevision{}, "Revision")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.historyCollection()
+		err = db.ensureCompoundIndex(collection, "revisionInstanceIdRevisionIndex", true, true, revisionInstanceIdKey, revisionKey)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) historyCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoHistoryCollection)
+}
+
+func (this *Mongo) AddInstanceRevision(ctx context.Context, revision model.InstanceRevision) error {
+	_, err := this.historyCollection().InsertOne(ctx, revision)
+	return err
+}
+
+// ListInstanceRevisions returns the revisions of an instance, newest first
+func (this *Mongo) ListInstanceRevisions(ctx context.Context, instanceId string, limit int64, offset int64) (result []model.InstanceRevision, err error) {
+	opt := options.Find()
+	opt.SetLimit(limit)
+	opt.SetSkip(offset)
+	opt.SetSort(bson.D{{Key: revisionKey, Value: -1}})
+	cursor, err := this.historyCollection().Find(ctx, bson.M{revisionInstanceIdKey: instanceId}, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.InstanceRevision{}
+	for cursor.Next(context.Background()) {
+		revision := model.InstanceRevision{}
+		err = cursor.Decode(&revision)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, revision)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) GetInstanceRevision(ctx context.Context, instanceId string, revision int64) (result model.InstanceRevision, exists bool, err error) {
+	err = this.historyCollection().FindOne(ctx, bson.M{revisionInstanceIdKey: instanceId, revisionKey: revision}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return result, false, nil
+	}
+	return result, err == nil, err
+}
+
+func (this *Mongo) RemoveInstanceRevisions(ctx context.Context, instanceIds []string) error {
+	_, err := this.historyCollection().DeleteMany(ctx, bson.M{revisionInstanceIdKey: bson.M{"$in": instanceIds}})
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 	"go.mongodb.org/mongo-driver/bson"
@@ -38,6 +39,10 @@ const userIdFieldName = "UserId"
 const createdAtFieldName = "CreatedAt"
 const updatedAtFieldName = "UpdatedAt"
 const generatedFieldName = "Generated"
+const templateIdFieldName = "TemplateId"
+const deletedAtFieldName = "DeletedAt"
+const expiresAtFieldName = "ExpiresAt"
+const revisionFieldName = "Revision"
 
 var idKey string
 var nameKey string
@@ -48,6 +53,10 @@ var ownerKey string
 var createdAtKey string
 var updatedAtKey string
 var generatedKey string
+var templateIdRefKey string
+var deletedAtKey string
+var expiresAtKey string
+var instanceRevisionKey string
 
 func init() {
 	var err error
@@ -87,6 +96,22 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	templateIdRefKey, err = getBsonFieldName(model.Instance{}, templateIdFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deletedAtKey, err = getBsonFieldName(model.Instance{}, deletedAtFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	expiresAtKey, err = getBsonFieldName(model.Instance{}, expiresAtFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	instanceRevisionKey, err = getBsonFieldName(model.Instance{}, revisionFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	CreateCollections = append(CreateCollections, func(db *Mongo) error {
 		collection := db.client.Database(db.config.MongoTable).Collection(db.config.MongoImportTypeCollection)
@@ -94,6 +119,14 @@ func init() {
 		if err != nil {
 			return err
 		}
+		err = db.ensureIndex(collection, "instanceDeletedAtIndex", deletedAtKey, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "instanceExpiresAtIndex", expiresAtKey, true, false)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -103,7 +136,7 @@ func (this *Mongo) instanceCollection() *mongo.Collection {
 }
 
 func (this *Mongo) GetInstance(ctx context.Context, id string) (instance model.Instance, exists bool, err error) {
-	result := this.instanceCollection().FindOne(ctx, bson.M{idKey: id})
+	result := this.instanceCollection().FindOne(ctx, bson.M{idKey: id, deletedAtKey: nil})
 	err = result.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -118,7 +151,8 @@ func (this *Mongo) GetInstance(ctx context.Context, id string) (instance model.I
 	return instance, true, err
 }
 
-func (this *Mongo) ListInstances(ctx context.Context, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, ids []string) (result []model.Instance, err error) {
+// ListInstances lists active instances. ids and expiresBefore are ignored if nil.
+func (this *Mongo) ListInstances(ctx context.Context, limit int64, offset int64, sort string, asc bool, search string, includeGenerated bool, ids []string, expiresBefore *time.Time) (result []model.Instance, err error) {
 	opt := options.Find()
 	opt.SetLimit(limit)
 	opt.SetSkip(offset)
@@ -133,6 +167,8 @@ func (this *Mongo) ListInstances(ctx context.Context, limit int64, offset int64,
 		sortby = createdAtKey
 	case "updated_at":
 		sortby = updatedAtKey
+	case "expires_at":
+		sortby = expiresAtKey
 	default:
 		sortby = idKey
 	}
@@ -140,8 +176,33 @@ func (this *Mongo) ListInstances(ctx context.Context, limit int64, offset int64,
 	if !asc {
 		direction = int32(-1)
 	}
-	opt.SetSort(bson.D{{sortby, direction}})
+	opt.SetSort(bson.D{{Key: sortby, Value: direction}})
 
+	filter := listInstancesFilter(search, includeGenerated, ids, expiresBefore)
+	cursor, err := this.instanceCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return nil, err
+	}
+	for cursor.Next(context.Background()) {
+		instance := model.Instance{}
+		err = cursor.Decode(&instance)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, instance)
+	}
+	if cursor.Err() != nil {
+		return nil, cursor.Err()
+	}
+	return
+}
+
+// CountListedInstances counts the instances matched by ListInstances with the same filter arguments
+func (this *Mongo) CountListedInstances(ctx context.Context, search string, includeGenerated bool, ids []string, expiresBefore *time.Time) (count int64, err error) {
+	return this.instanceCollection().CountDocuments(ctx, listInstancesFilter(search, includeGenerated, ids, expiresBefore))
+}
+
+func listInstancesFilter(search string, includeGenerated bool, ids []string, expiresBefore *time.Time) bson.M {
 	searchKey := nameKey
 	searchSplit := strings.Split(search, ":")
 	if len(searchSplit) > 1 {
@@ -174,22 +235,11 @@ func (this *Mongo) ListInstances(ctx context.Context, limit int64, offset int64,
 	if ids != nil {
 		filter[idKey] = bson.M{"$in": ids}
 	}
-	cursor, err := this.instanceCollection().Find(ctx, filter, opt)
-	if err != nil {
-		return nil, err
-	}
-	for cursor.Next(context.Background()) {
-		instance := model.Instance{}
-		err = cursor.Decode(&instance)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, instance)
-	}
-	if cursor.Err() != nil {
-		return nil, cursor.Err()
+	if expiresBefore != nil {
+		filter[expiresAtKey] = bson.M{"$ne": nil, "$lt": *expiresBefore}
 	}
-	return
+	filter[deletedAtKey] = nil
+	return filter
 }
 
 func (this *Mongo) SetInstance(ctx context.Context, instance model.Instance) error {
@@ -200,6 +250,20 @@ func (this *Mongo) SetInstance(ctx context.Context, instance model.Instance) err
 	return err
 }
 
+// ReplaceInstance replaces the stored instance only if it still has the given revision.
+// matched is false if the instance has been changed, moved to the trash or removed in the meantime.
+func (this *Mongo) ReplaceInstance(ctx context.Context, instance model.Instance, revision int64) (matched bool, err error) {
+	filter := bson.M{idKey: instance.Id, instanceRevisionKey: revision}
+	if revision == 0 {
+		filter[instanceRevisionKey] = bson.M{"$in": bson.A{0, nil}} // stored before revisions were introduced
+	}
+	result, err := this.instanceCollection().ReplaceOne(ctx, filter, instance)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
 func (this *Mongo) RemoveInstances(ctx context.Context, ids []string) error {
 	filter := bson.M{idKey: bson.M{"$in": ids}}
 	_, err := this.instanceCollection().DeleteMany(ctx, filter)
@@ -207,7 +271,15 @@ func (this *Mongo) RemoveInstances(ctx context.Context, ids []string) error {
 }
 
 func (this *Mongo) GetInstances(ctx context.Context, ids []string) (result []model.Instance, allExist bool, err error) {
-	filter := bson.M{idKey: bson.M{"$in": ids}}
+	return this.getInstances(ctx, bson.M{idKey: bson.M{"$in": ids}, deletedAtKey: nil}, ids)
+}
+
+// GetDeletedInstances returns the given instances if they are in the trash
+func (this *Mongo) GetDeletedInstances(ctx context.Context, ids []string) (result []model.Instance, allExist bool, err error) {
+	return this.getInstances(ctx, bson.M{idKey: bson.M{"$in": ids}, deletedAtKey: bson.M{"$ne": nil}}, ids)
+}
+
+func (this *Mongo) getInstances(ctx context.Context, filter bson.M, ids []string) (result []model.Instance, allExist bool, err error) {
 	cursor, err := this.instanceCollection().Find(ctx, filter)
 	if err != nil {
 		return result, false, err
@@ -223,3 +295,56 @@ func (this *Mongo) GetInstances(ctx context.Context, ids []string) (result []mod
 
 	return result, len(result) == len(ids), nil
 }
+
+// CountInstances counts the instances of the owner, excluding the trash
+func (this *Mongo) CountInstances(ctx context.Context, userId string) (count int64, err error) {
+	return this.instanceCollection().CountDocuments(ctx, bson.M{ownerKey: userId, deletedAtKey: nil})
+}
+
+func (this *Mongo) ListInstanceIdsByTemplate(ctx context.Context, templateId string) (ids []string, err error) {
+	cursor, err := this.instanceCollection().Find(ctx, bson.M{templateIdRefKey: templateId, deletedAtKey: nil}, options.Find().SetProjection(bson.M{idKey: 1}))
+	if err != nil {
+		return nil, err
+	}
+	ids = []string{}
+	for cursor.Next(context.Background()) {
+		instance := model.Instance{}
+		err = cursor.Decode(&instance)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, instance.Id)
+	}
+	return ids, cursor.Err()
+}
+
+// ListDeletedInstances lists instances in the trash, most recently deleted first.
+// ids and deletedBefore are ignored if nil.
+func (this *Mongo) ListDeletedInstances(ctx context.Context, limit int64, offset int64, ids []string, deletedBefore *time.Time) (result []model.Instance, err error) {
+	opt := options.Find()
+	opt.SetLimit(limit)
+	opt.SetSkip(offset)
+	opt.SetSort(bson.D{{Key: deletedAtKey, Value: -1}})
+	deletedAt := bson.M{"$ne": nil}
+	if deletedBefore != nil {
+		deletedAt["$lt"] = *deletedBefore
+	}
+	filter := bson.M{deletedAtKey: deletedAt}
+	if ids != nil {
+		filter[idKey] = bson.M{"$in": ids}
+	}
+	cursor, err := this.instanceCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.Instance{}
+	for cursor.Next(context.Background()) {
+		instance := model.Instance{}
+		err = cursor.Decode(&instance)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, instance)
+	}
+	return result, cursor.Err()
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var lockKeyKey string
+var lockOwnerKey string
+var lockLockedUntilKey string
+
+func init() {
+	var err error
+	lockKeyKey, err = getBsonFieldName(model.Lock{}, "Key")
+	if err != nil {
+		log.Fatal(err)
+	}
+	lockOwnerKey, err = getBsonFieldName(model.Lock{}, "Owner")
+	if err != nil {
+		log.Fatal(err)
+	}
+	lockLockedUntilKey, err = getBsonFieldName(model.Lock{}, "LockedUntil")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.lockCollection()
+		err = db.ensureIndex(collection, "lockKeyIndex", lockKeyKey, true, true)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) lockCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoLockCollection)
+}
+
+// AcquireLock takes the lock if it is free, expired or already held by owner.
+// acquired is false if another owner holds it.
+func (this *Mongo) AcquireLock(ctx context.Context, key string, owner string, lockedUntil time.Time) (acquired bool, err error) {
+	filter := bson.M{lockKeyKey: key, "$or": []bson.M{
+		{lockOwnerKey: owner},
+		{lockLockedUntilKey: bson.M{"$lt": time.Now()}},
+	}}
+	update := bson.M{"$set": bson.M{lockOwnerKey: owner, lockLockedUntilKey: lockedUntil}}
+	_, err = this.lockCollection().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil // the upsert collided with the lock of another owner
+	}
+	return err == nil, err
+}
+
+// ExtendLock moves the expiry of a lock held by owner. locked is false if the lock expired and has been taken by another owner.
+func (this *Mongo) ExtendLock(ctx context.Context, key string, owner string, lockedUntil time.Time) (locked bool, err error) {
+	result, err := this.lockCollection().UpdateOne(ctx, bson.M{lockKeyKey: key, lockOwnerKey: owner}, bson.M{"$set": bson.M{lockLockedUntilKey: lockedUntil}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ReleaseLock removes the lock if it is still held by owner
+func (this *Mongo) ReleaseLock(ctx context.Context, key string, owner string) error {
+	_, err := this.lockCollection().DeleteOne(ctx, bson.M{lockKeyKey: key, lockOwnerKey: owner})
+	return err
+}
@@ -109,7 +109,7 @@ func (this *Mongo) ensureIndex(collection *mongo.Collection, indexname string, i
 		direction = 1
 	}
 	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{indexKey, direction}},
+		Keys:    bson.D{{Key: indexKey, Value: direction}},
 		Options: options.Index().SetName(indexname).SetUnique(unique),
 	})
 	if err != nil {
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var operationIdKey string
+var operationStatusKey string
+var operationCreatedAtKey string
+var operationFinishedAtKey string
+var operationStartedAtKey string
+var operationAttemptsKey string
+var operationLockedByKey string
+var operationLockedUntilKey string
+
+func init() {
+	var err error
+	operationIdKey, err = getBsonFieldName(model.Operation{}, "Id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationStatusKey, err = getBsonFieldName(model.Operation{}, "Status")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationCreatedAtKey, err = getBsonFieldName(model.Operation{}, "CreatedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationFinishedAtKey, err = getBsonFieldName(model.Operation{}, "FinishedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationStartedAtKey, err = getBsonFieldName(model.Operation{}, "StartedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationAttemptsKey, err = getBsonFieldName(model.Operation{}, "Attempts")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationLockedByKey, err = getBsonFieldName(model.Operation{}, "LockedBy")
+	if err != nil {
+		log.Fatal(err)
+	}
+	operationLockedUntilKey, err = getBsonFieldName(model.Operation{}, "LockedUntil")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.operationCollection()
+		err = db.ensureIndex(collection, "operationIdIndex", operationIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "operationStatusCreatedAtIndex", true, false, operationStatusKey, operationCreatedAtKey)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "operationFinishedAtIndex", operationFinishedAtKey, true, false)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) operationCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoOperationCollection)
+}
+
+func (this *Mongo) AddOperation(ctx context.Context, operation model.Operation) error {
+	_, err := this.operationCollection().InsertOne(ctx, operation)
+	return err
+}
+
+func (this *Mongo) GetOperation(ctx context.Context, id string) (operation model.Operation, exists bool, err error) {
+	err = this.operationCollection().FindOne(ctx, bson.M{operationIdKey: id}).Decode(&operation)
+	if err == mongo.ErrNoDocuments {
+		return operation, false, nil
+	}
+	if err != nil {
+		return operation, false, err
+	}
+	return operation, true, nil
+}
+
+// ClaimOperation locks the oldest pending operation, or a running one whose lock expired, for the owner until lockedUntil.
+// found is false if no operation is waiting.
+func (this *Mongo) ClaimOperation(ctx context.Context, owner string, lockedUntil time.Time) (operation model.Operation, found bool, err error) {
+	now := time.Now()
+	filter := bson.M{"$or": []bson.M{
+		{operationStatusKey: model.OperationStatusPending},
+		{operationStatusKey: model.OperationStatusRunning, operationLockedUntilKey: bson.M{"$lt": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{
+			operationStatusKey:      model.OperationStatusRunning,
+			operationStartedAtKey:   now,
+			operationLockedByKey:    owner,
+			operationLockedUntilKey: lockedUntil,
+		},
+		"$inc": bson.M{operationAttemptsKey: 1},
+	}
+	opt := options.FindOneAndUpdate().SetSort(bson.D{{Key: operationCreatedAtKey, Value: 1}}).SetReturnDocument(options.After)
+	err = this.operationCollection().FindOneAndUpdate(ctx, filter, update, opt).Decode(&operation)
+	if err == mongo.ErrNoDocuments {
+		return operation, false, nil
+	}
+	if err != nil {
+		return operation, false, err
+	}
+	return operation, true, nil
+}
+
+// ExtendOperationLock extends the lock of a running operation, locked is false if the owner lost it
+func (this *Mongo) ExtendOperationLock(ctx context.Context, id string, owner string, lockedUntil time.Time) (locked bool, err error) {
+	result, err := this.operationCollection().UpdateOne(ctx, bson.M{operationIdKey: id, operationLockedByKey: owner, operationStatusKey: model.OperationStatusRunning}, bson.M{"$set": bson.M{operationLockedUntilKey: lockedUntil}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// FinishOperation stores the finished operation if the owner still holds its lock, locked is false otherwise
+func (this *Mongo) FinishOperation(ctx context.Context, operation model.Operation, owner string) (locked bool, err error) {
+	result, err := this.operationCollection().ReplaceOne(ctx, bson.M{operationIdKey: operation.Id, operationLockedByKey: owner, operationStatusKey: model.OperationStatusRunning}, operation)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// RemoveFinishedOperations removes operations which finished before the given time
+func (this *Mongo) RemoveFinishedOperations(ctx context.Context, finishedBefore time.Time) error {
+	_, err := this.operationCollection().DeleteMany(ctx, bson.M{operationFinishedAtKey: bson.M{"$lt": finishedBefore}})
+	return err
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var outboxIdKey string
+var outboxTimestampKey string
+
+func init() {
+	var err error
+	outboxIdKey, err = getBsonFieldName(model.LifecycleEvent{}, "Id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	outboxTimestampKey, err = getBsonFieldName(model.LifecycleEvent{}, "Timestamp")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.outboxCollection()
+		err = db.ensureIndex(collection, "outboxIdIndex", outboxIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "outboxTimestampIndex", outboxTimestampKey, true, false)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) outboxCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoOutboxCollection)
+}
+
+func (this *Mongo) AddOutboxEvent(ctx context.Context, event model.LifecycleEvent) error {
+	_, err := this.outboxCollection().InsertOne(ctx, event)
+	return err
+}
+
+// ListOutboxEvents returns the oldest events waiting to be published in the order they were added
+func (this *Mongo) ListOutboxEvents(ctx context.Context, limit int64) (result []model.LifecycleEvent, err error) {
+	opt := options.Find().SetLimit(limit).SetSort(bson.D{{Key: outboxTimestampKey, Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := this.outboxCollection().Find(ctx, bson.M{}, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.LifecycleEvent{}
+	for cursor.Next(context.Background()) {
+		event := model.LifecycleEvent{}
+		err = cursor.Decode(&event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) RemoveOutboxEvents(ctx context.Context, ids []string) error {
+	_, err := this.outboxCollection().DeleteMany(ctx, bson.M{outboxIdKey: bson.M{"$in": ids}})
+	return err
+}
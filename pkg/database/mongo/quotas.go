@@ -0,0 +1,99 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var quotaTypeKey string
+var quotaSubjectKey string
+
+func init() {
+	var err error
+	quotaTypeKey, err = getBsonFieldName(model.Quota{}, "Type")
+	if err != nil {
+		log.Fatal(err)
+	}
+	quotaSubjectKey, err = getBsonFieldName(model.Quota{}, "Subject")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.quotaCollection()
+		err = db.ensureCompoundIndex(collection, "quotaTypeSubjectIndex", true, true, quotaTypeKey, quotaSubjectKey)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) quotaCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoQuotaCollection)
+}
+
+func (this *Mongo) ListQuotas(ctx context.Context) (result []model.Quota, err error) {
+	opt := options.Find().SetSort(bson.D{{Key: quotaTypeKey, Value: 1}, {Key: quotaSubjectKey, Value: 1}})
+	return this.findQuotas(ctx, bson.M{}, opt)
+}
+
+// GetQuotas returns the overrides of the user and of the given roles
+func (this *Mongo) GetQuotas(ctx context.Context, userId string, roles []string) (result []model.Quota, err error) {
+	filter := bson.M{"$or": []bson.M{
+		{quotaTypeKey: model.QuotaTypeUser, quotaSubjectKey: userId},
+		{quotaTypeKey: model.QuotaTypeRole, quotaSubjectKey: bson.M{"$in": roles}},
+	}}
+	return this.findQuotas(ctx, filter, options.Find().SetSort(bson.D{{Key: quotaSubjectKey, Value: 1}}))
+}
+
+func (this *Mongo) findQuotas(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (result []model.Quota, err error) {
+	cursor, err := this.quotaCollection().Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.Quota{}
+	for cursor.Next(context.Background()) {
+		quota := model.Quota{}
+		err = cursor.Decode(&quota)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, quota)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) SetQuota(ctx context.Context, quota model.Quota) error {
+	_, err := this.quotaCollection().ReplaceOne(ctx, bson.M{quotaTypeKey: quota.Type, quotaSubjectKey: quota.Subject}, quota, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (this *Mongo) RemoveQuota(ctx context.Context, quotaType string, subject string) (exists bool, err error) {
+	result, err := this.quotaCollection().DeleteOne(ctx, bson.M{quotaTypeKey: quotaType, quotaSubjectKey: subject})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var templateIdKey string
+var templateNameKey string
+
+func init() {
+	var err error
+	templateIdKey, err = getBsonFieldName(model.Template{}, idFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	templateNameKey, err = getBsonFieldName(model.Template{}, nameFieldName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.templateCollection()
+		err = db.ensureIndex(collection, "templateIdIndex", templateIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) templateCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoTemplateCollection)
+}
+
+func (this *Mongo) ListTemplates(ctx context.Context, limit int64, offset int64, ids []string) (result []model.Template, err error) {
+	opt := options.Find()
+	opt.SetLimit(limit)
+	opt.SetSkip(offset)
+	opt.SetSort(bson.D{{Key: templateNameKey, Value: 1}})
+	cursor, err := this.templateCollection().Find(ctx, bson.M{templateIdKey: bson.M{"$in": ids}}, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.Template{}
+	for cursor.Next(context.Background()) {
+		template := model.Template{}
+		err = cursor.Decode(&template)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, template)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) GetTemplate(ctx context.Context, id string) (template model.Template, exists bool, err error) {
+	result := this.templateCollection().FindOne(ctx, bson.M{templateIdKey: id})
+	err = result.Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return template, false, errors.New("requested template nonexistent")
+		}
+		return template, false, err
+	}
+	err = result.Decode(&template)
+	return template, err == nil, err
+}
+
+func (this *Mongo) SetTemplate(ctx context.Context, template model.Template) error {
+	_, err := this.templateCollection().ReplaceOne(ctx, bson.M{templateIdKey: template.Id}, template, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (this *Mongo) RemoveTemplate(ctx context.Context, id string) error {
+	_, err := this.templateCollection().DeleteOne(ctx, bson.M{templateIdKey: id})
+	return err
+}
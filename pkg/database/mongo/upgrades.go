@@ -0,0 +1,89 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var upgradeRunningKey string
+var upgradeCancelRequestedKey string
+
+func init() {
+	var err error
+	upgradeRunningKey, err = getBsonFieldName(model.UpgradeProgress{}, "Running")
+	if err != nil {
+		log.Fatal(err)
+	}
+	upgradeCancelRequestedKey, err = getBsonFieldName(model.UpgradeProgress{}, "CancelRequested")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// the collection holds a single document, the progress of the current or last upgrade
+func (this *Mongo) upgradeCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoUpgradeCollection)
+}
+
+func (this *Mongo) GetUpgradeProgress(ctx context.Context) (progress model.UpgradeProgress, exists bool, err error) {
+	err = this.upgradeCollection().FindOne(ctx, bson.M{}).Decode(&progress)
+	if err == mongo.ErrNoDocuments {
+		return progress, false, nil
+	}
+	if err != nil {
+		return progress, false, err
+	}
+	return progress, true, nil
+}
+
+// SetUpgradeProgress replaces the progress of the last upgrade, including its cancel request
+func (this *Mongo) SetUpgradeProgress(ctx context.Context, progress model.UpgradeProgress) error {
+	_, err := this.upgradeCollection().ReplaceOne(ctx, bson.M{}, progress, options.Replace().SetUpsert(true))
+	return err
+}
+
+// UpdateUpgradeProgress replaces the stored progress but keeps a cancel request stored in the meantime
+func (this *Mongo) UpdateUpgradeProgress(ctx context.Context, progress model.UpgradeProgress) error {
+	if !progress.CancelRequested {
+		result, err := this.upgradeCollection().ReplaceOne(ctx, bson.M{upgradeCancelRequestedKey: bson.M{"$ne": true}}, progress)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+		progress.CancelRequested = true
+	}
+	_, err := this.upgradeCollection().ReplaceOne(ctx, bson.M{}, progress)
+	return err
+}
+
+// RequestUpgradeCancel marks the stored upgrade as cancelled. running is false if no upgrade is running.
+func (this *Mongo) RequestUpgradeCancel(ctx context.Context) (running bool, err error) {
+	result, err := this.upgradeCollection().UpdateOne(ctx, bson.M{upgradeRunningKey: true}, bson.M{"$set": bson.M{upgradeCancelRequestedKey: true}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
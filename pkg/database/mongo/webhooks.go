@@ -0,0 +1,276 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var webhookIdKey string
+var webhookUserIdKey string
+var webhookCreatedAtKey string
+var webhookDeliveryIdKey string
+var webhookDeliveryWebhookIdKey string
+var webhookDeliveryStatusKey string
+var webhookDeliveryCreatedAtKey string
+var webhookDeliveryNextAttemptAtKey string
+var webhookDeliveryFinishedAtKey string
+var instanceStateInstanceIdKey string
+var instanceStateStateKey string
+var instanceStateChangedAtKey string
+
+func init() {
+	var err error
+	webhookIdKey, err = getBsonFieldName(model.Webhook{}, "Id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookUserIdKey, err = getBsonFieldName(model.Webhook{}, "UserId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookCreatedAtKey, err = getBsonFieldName(model.Webhook{}, "CreatedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryIdKey, err = getBsonFieldName(model.WebhookDelivery{}, "Id")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryWebhookIdKey, err = getBsonFieldName(model.WebhookDelivery{}, "WebhookId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryStatusKey, err = getBsonFieldName(model.WebhookDelivery{}, "Status")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryCreatedAtKey, err = getBsonFieldName(model.WebhookDelivery{}, "CreatedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryNextAttemptAtKey, err = getBsonFieldName(model.WebhookDelivery{}, "NextAttemptAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	webhookDeliveryFinishedAtKey, err = getBsonFieldName(model.WebhookDelivery{}, "FinishedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	instanceStateInstanceIdKey, err = getBsonFieldName(model.InstanceState{}, "InstanceId")
+	if err != nil {
+		log.Fatal(err)
+	}
+	instanceStateStateKey, err = getBsonFieldName(model.InstanceState{}, "State")
+	if err != nil {
+		log.Fatal(err)
+	}
+	instanceStateChangedAtKey, err = getBsonFieldName(model.InstanceState{}, "ChangedAt")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	CreateCollections = append(CreateCollections, func(db *Mongo) error {
+		collection := db.webhookCollection()
+		err = db.ensureIndex(collection, "webhookIdIndex", webhookIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "webhookUserIdIndex", webhookUserIdKey, true, false)
+		if err != nil {
+			return err
+		}
+		collection = db.webhookDeliveryCollection()
+		err = db.ensureIndex(collection, "webhookDeliveryIdIndex", webhookDeliveryIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "webhookDeliveryWebhookIdCreatedAtIndex", false, false, webhookDeliveryWebhookIdKey, webhookDeliveryCreatedAtKey)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "webhookDeliveryStatusNextAttemptAtIndex", true, false, webhookDeliveryStatusKey, webhookDeliveryNextAttemptAtKey)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "webhookDeliveryFinishedAtIndex", webhookDeliveryFinishedAtKey, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(db.instanceStateCollection(), "instanceStateInstanceIdIndex", instanceStateInstanceIdKey, true, true)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Mongo) webhookCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoWebhookCollection)
+}
+
+func (this *Mongo) webhookDeliveryCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoWebhookDeliveryCollection)
+}
+
+func (this *Mongo) instanceStateCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoTable).Collection(this.config.MongoInstanceStateCollection)
+}
+
+// ListWebhooks returns the webhooks of the user, or of all users if userId is empty
+func (this *Mongo) ListWebhooks(ctx context.Context, userId string) (result []model.Webhook, err error) {
+	filter := bson.M{}
+	if userId != "" {
+		filter[webhookUserIdKey] = userId
+	}
+	cursor, err := this.webhookCollection().Find(ctx, filter, options.Find().SetSort(bson.D{{Key: webhookCreatedAtKey, Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	result = []model.Webhook{}
+	for cursor.Next(context.Background()) {
+		webhook := model.Webhook{}
+		err = cursor.Decode(&webhook)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, webhook)
+	}
+	return result, cursor.Err()
+}
+
+func (this *Mongo) GetWebhook(ctx context.Context, id string) (webhook model.Webhook, exists bool, err error) {
+	err = this.webhookCollection().FindOne(ctx, bson.M{webhookIdKey: id}).Decode(&webhook)
+	if err == mongo.ErrNoDocuments {
+		return webhook, false, nil
+	}
+	if err != nil {
+		return webhook, false, err
+	}
+	return webhook, true, nil
+}
+
+func (this *Mongo) AddWebhook(ctx context.Context, webhook model.Webhook) error {
+	_, err := this.webhookCollection().InsertOne(ctx, webhook)
+	return err
+}
+
+// RemoveWebhook removes the webhook and its delivery log
+func (this *Mongo) RemoveWebhook(ctx context.Context, id string) (exists bool, err error) {
+	result, err := this.webhookCollection().DeleteOne(ctx, bson.M{webhookIdKey: id})
+	if err != nil {
+		return false, err
+	}
+	_, err = this.webhookDeliveryCollection().DeleteMany(ctx, bson.M{webhookDeliveryWebhookIdKey: id})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (this *Mongo) AddWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	_, err := this.webhookDeliveryCollection().InsertOne(ctx, delivery)
+	return err
+}
+
+func (this *Mongo) SetWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	_, err := this.webhookDeliveryCollection().ReplaceOne(ctx, bson.M{webhookDeliveryIdKey: delivery.Id}, delivery)
+	return err
+}
+
+// ClaimWebhookDelivery returns the pending delivery which is due the longest and postpones its next attempt to lockedUntil,
+// so that no other replica attempts it meanwhile. found is false if no delivery is due.
+func (this *Mongo) ClaimWebhookDelivery(ctx context.Context, now time.Time, lockedUntil time.Time) (delivery model.WebhookDelivery, found bool, err error) {
+	filter := bson.M{webhookDeliveryStatusKey: model.WebhookDeliveryPending, webhookDeliveryNextAttemptAtKey: bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{webhookDeliveryNextAttemptAtKey: lockedUntil}}
+	opt := options.FindOneAndUpdate().SetSort(bson.D{{Key: webhookDeliveryNextAttemptAtKey, Value: 1}}).SetReturnDocument(options.After)
+	err = this.webhookDeliveryCollection().FindOneAndUpdate(ctx, filter, update, opt).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return delivery, false, nil
+	}
+	if err != nil {
+		return delivery, false, err
+	}
+	return delivery, true, nil
+}
+
+// ListWebhookDeliveries returns the delivery log of the webhook, newest first
+func (this *Mongo) ListWebhookDeliveries(ctx context.Context, webhookId string, limit int64, offset int64) (result []model.WebhookDelivery, err error) {
+	opt := options.Find().SetLimit(limit).SetSkip(offset).SetSort(bson.D{{Key: webhookDeliveryCreatedAtKey, Value: -1}})
+	cursor, err := this.webhookDeliveryCollection().Find(ctx, bson.M{webhookDeliveryWebhookIdKey: webhookId}, opt)
+	if err != nil {
+		return nil, err
+	}
+	result = []model.WebhookDelivery{}
+	for cursor.Next(context.Background()) {
+		delivery := model.WebhookDelivery{}
+		err = cursor.Decode(&delivery)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, delivery)
+	}
+	return result, cursor.Err()
+}
+
+// RemoveFinishedWebhookDeliveries removes deliveries which succeeded or failed finally before the given time
+func (this *Mongo) RemoveFinishedWebhookDeliveries(ctx context.Context, finishedBefore time.Time) error {
+	_, err := this.webhookDeliveryCollection().DeleteMany(ctx, bson.M{webhookDeliveryFinishedAtKey: bson.M{"$lt": finishedBefore}})
+	return err
+}
+
+func (this *Mongo) GetInstanceStates(ctx context.Context, ids []string) (result []model.InstanceState, err error) {
+	cursor, err := this.instanceStateCollection().Find(ctx, bson.M{instanceStateInstanceIdKey: bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	result = []model.InstanceState{}
+	for cursor.Next(context.Background()) {
+		state := model.InstanceState{}
+		err = cursor.Decode(&state)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, state)
+	}
+	return result, cursor.Err()
+}
+
+// SetInstanceState stores the state if the stored state is still previous, an empty previous expects no stored state.
+// changed is false if another replica changed the state first.
+func (this *Mongo) SetInstanceState(ctx context.Context, state model.InstanceState, previous string) (changed bool, err error) {
+	if previous == "" {
+		_, err = this.instanceStateCollection().InsertOne(ctx, state)
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	filter := bson.M{instanceStateInstanceIdKey: state.InstanceId, instanceStateStateKey: previous}
+	result, err := this.instanceStateCollection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{instanceStateStateKey: state.State, instanceStateChangedAtKey: state.ChangedAt}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
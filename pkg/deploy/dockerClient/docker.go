@@ -19,12 +19,19 @@ package dockerClient
 import (
 	"context"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	docker "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
 	"log"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 type DockerClient struct {
@@ -61,7 +68,8 @@ func (this *DockerClient) CreateContainer(name string, image string, _ string, e
 	}
 	var restartPolicy container.RestartPolicy
 	if restart {
-		restartPolicy = container.RestartPolicy{Name: "always"}
+		// unlike always, workloads stopped for paused or unscheduled instances stay stopped when the daemon restarts
+		restartPolicy = container.RestartPolicy{Name: "unless-stopped"}
 	} else {
 		restartPolicy = container.RestartPolicy{Name: "no"}
 	}
@@ -116,3 +124,132 @@ func (this *DockerClient) ContainerExists(id string) (exists bool, err error) {
 	}
 	return true, nil
 }
+
+// StopContainer stops the container. Containers created with the restart policy always, which the daemon
+// restarts after its own restart even if they were stopped, are switched to unless-stopped first.
+func (this *DockerClient) StopContainer(id string) (err error) {
+	err = this.migrateRestartPolicy(id)
+	if err != nil {
+		return err
+	}
+	return this.stopContainer(id)
+}
+
+func (this *DockerClient) StartContainer(id string) (err error) {
+	ctx, _ := util.GetTimeoutContext()
+	return this.cli.ContainerStart(ctx, id, types.ContainerStartOptions{})
+}
+
+func (this *DockerClient) GetContainerConfig(id string) (deployed model.DeployedConfig, exists bool, err error) {
+	ctx, _ := util.GetTimeoutContext()
+	info, err := this.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		if docker.IsErrNotFound(err) {
+			return deployed, false, nil
+		}
+		return deployed, false, err
+	}
+	deployed.Env = map[string]string{}
+	if info.Config == nil {
+		return deployed, true, nil
+	}
+	deployed.Image = info.Config.Image
+	for _, e := range info.Config.Env {
+		key, value, _ := strings.Cut(e, "=")
+		deployed.Env[key] = value
+	}
+	return deployed, true, nil
+}
+
+func (this *DockerClient) ListContainers(namePrefix string) (workloads []model.Workload, err error) {
+	ctx, _ := util.GetTimeoutContext()
+	containers, err := this.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", namePrefix)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	workloads = []model.Workload{}
+	for _, c := range containers {
+		for _, name := range c.Names {
+			// the name filter of docker matches substrings
+			name = strings.TrimPrefix(name, "/")
+			if strings.HasPrefix(name, namePrefix) {
+				workloads = append(workloads, model.Workload{Id: c.ID, Name: name, CreatedAt: time.Unix(c.Created, 0)})
+				break
+			}
+		}
+	}
+	return workloads, nil
+}
+
+// restart count of a restarting container from which on it is considered to be crash looping
+const crashLoopRestartCount = 3
+
+func (this *DockerClient) GetContainerStatus(id string) (status model.DeploymentStatus, err error) {
+	ctx, _ := util.GetTimeoutContext()
+	info, err := this.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		if docker.IsErrNotFound(err) {
+			return model.DeploymentStatus{State: model.DeploymentStateMissing}, nil
+		}
+		return status, err
+	}
+	status.Restarts = info.RestartCount
+	if info.State == nil {
+		status.State = model.DeploymentStateUnknown
+		return status, nil
+	}
+	status.BackendState = info.State.Status
+	status.Message = info.State.Error
+	switch {
+	case info.State.Restarting && info.RestartCount >= crashLoopRestartCount:
+		status.State = model.DeploymentStateCrashLoop
+	case info.State.Restarting:
+		status.State = model.DeploymentStateRestarting
+	case info.State.Running:
+		status.State = model.DeploymentStateRunning
+	case info.State.Dead || info.State.OOMKilled:
+		status.State = model.DeploymentStateFailed
+	case info.State.Status == "exited" || info.State.Status == "created":
+		status.State = model.DeploymentStateStopped
+		if status.Message == "" && info.State.ExitCode != 0 {
+			status.Message = "exit code " + strconv.Itoa(info.State.ExitCode)
+		}
+	default:
+		status.State = model.DeploymentStateUnknown
+	}
+	return status, nil
+}
+
+func (this *DockerClient) GetContainerLogs(id string, options model.LogOptions) (logs io.ReadCloser, err error) {
+	// followed logs are open ended, the stream is terminated by closing the returned reader
+	ctx, cancel := context.WithCancel(context.Background())
+	logOptions := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     options.Follow,
+		Timestamps: options.Timestamps,
+		Tail:       "all",
+	}
+	if options.Tail > 0 {
+		logOptions.Tail = strconv.Itoa(options.Tail)
+	}
+	if !options.Since.IsZero() {
+		logOptions.Since = strconv.FormatInt(options.Since.Unix(), 10)
+	}
+	stream, err := this.cli.ContainerLogs(ctx, id, logOptions)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// containers are created without tty, so stdout and stderr are multiplexed into one stream
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(writer, writer, stream)
+		_ = stream.Close()
+		_ = writer.CloseWithError(err)
+	}()
+	return &logReader{ReadCloser: reader, cancel: cancel}, nil
+}
@@ -18,8 +18,10 @@ package dockerClient
 
 import (
 	"context"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"io"
 )
 
 func (this *DockerClient) listAllContainers() (containers []types.Container, err error) {
@@ -65,6 +67,19 @@ func (this *DockerClient) stopContainer(id string) (err error) {
 	return err
 }
 
+func (this *DockerClient) migrateRestartPolicy(id string) (err error) {
+	ctx, _ := util.GetTimeoutContext()
+	info, err := this.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return err
+	}
+	if info.HostConfig == nil || info.HostConfig.RestartPolicy.Name != "always" {
+		return nil
+	}
+	_, err = this.cli.ContainerUpdate(ctx, id, container.UpdateConfig{RestartPolicy: container.RestartPolicy{Name: "unless-stopped"}})
+	return err
+}
+
 func (this *DockerClient) removeContainer(id string) (err error) {
 	ctx := context.Background()
 	removeOptions := types.ContainerRemoveOptions{Force: true}
@@ -72,3 +87,13 @@ func (this *DockerClient) removeContainer(id string) (err error) {
 	return this.cli.ContainerRemove(ctx, id, removeOptions)
 
 }
+
+type logReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (this *logReader) Close() error {
+	this.cancel()
+	return this.ReadCloser.Close()
+}
@@ -16,9 +16,24 @@
 
 package deploy
 
+import (
+	"errors"
+	"io"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+var ErrNotSupported = errors.New("operation not supported by deployment backend")
+
 type DeploymentClient interface {
 	CreateContainer(name string, image string, userid string, env map[string]string, restart bool) (id string, err error)
 	UpdateContainer(id string, name string, image string, userid string, env map[string]string, restart bool) (newId string, err error)
 	RemoveContainer(id string) (err error)
 	ContainerExists(id string) (exists bool, err error)
+	StopContainer(id string) (err error)
+	StartContainer(id string) (err error)
+	GetContainerStatus(id string) (status model.DeploymentStatus, err error)
+	GetContainerLogs(id string, options model.LogOptions) (logs io.ReadCloser, err error)
+	GetContainerConfig(id string) (deployed model.DeployedConfig, exists bool, err error)
+	ListContainers(namePrefix string) (workloads []model.Workload, err error)
 }
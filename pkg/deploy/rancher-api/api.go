@@ -21,11 +21,17 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/deploy"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
 	"github.com/hashicorp/go-uuid"
 	"github.com/parnurzeal/gorequest"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Rancher struct {
@@ -113,7 +119,7 @@ func (r Rancher) RemoveContainer(id string) (err error) {
 	return
 }
 
-func (r Rancher) UpdateContainer(id string, name string, _ string, image string, env map[string]string, restart bool) (newId string, err error) {
+func (r Rancher) UpdateContainer(id string, name string, image string, _ string, env map[string]string, restart bool) (newId string, err error) {
 	err = r.RemoveContainer(id)
 	if err != nil {
 		return newId, err
@@ -147,6 +153,121 @@ func (r Rancher) ContainerExists(id string) (exists bool, err error) {
 	return resp.StatusCode == http.StatusOK, nil
 }
 
+func (r Rancher) GetContainerStatus(id string) (status model.DeploymentStatus, err error) {
+	service, exists, err := r.getService(id)
+	if err != nil {
+		return status, err
+	}
+	if !exists {
+		return model.DeploymentStatus{State: model.DeploymentStateMissing}, nil
+	}
+	status.BackendState = service.State
+	if service.HealthState != "" {
+		status.BackendState += "/" + service.HealthState
+	}
+	status.Message = service.TransitioningMessage
+	switch service.State {
+	case "active", "upgraded":
+		if service.HealthState == "unhealthy" {
+			status.State = model.DeploymentStateFailed
+		} else {
+			status.State = model.DeploymentStateRunning
+		}
+	case "activating", "upgrading", "updating-active", "restarting", "finishing-upgrade", "rolling-back":
+		status.State = model.DeploymentStateRestarting
+	case "inactive", "deactivating", "updating-inactive":
+		status.State = model.DeploymentStateStopped
+	case "removed", "removing", "purged", "purging":
+		status.State = model.DeploymentStateMissing
+	case "error":
+		status.State = model.DeploymentStateFailed
+	default:
+		status.State = model.DeploymentStateUnknown
+	}
+	return status, nil
+}
+
+func (r Rancher) GetContainerConfig(id string) (deployed model.DeployedConfig, exists bool, err error) {
+	service, exists, err := r.getService(id)
+	if err != nil || !exists {
+		return deployed, exists, err
+	}
+	deployed.Image = strings.TrimPrefix(service.LaunchConfig.ImageUuid, "docker:")
+	deployed.Env = service.LaunchConfig.Environment
+	if deployed.Env == nil {
+		deployed.Env = map[string]string{}
+	}
+	return deployed, true, nil
+}
+
+func (r Rancher) ListContainers(namePrefix string) (workloads []model.Workload, err error) {
+	workloads = []model.Workload{}
+	next := r.url + "services?stackId=" + url.QueryEscape(r.stackId) + "&name_prefix=" + url.QueryEscape(namePrefix)
+	for next != "" {
+		request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey)
+		resp, body, errs := request.Get(next).End()
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+		}
+		services := ServiceCollection{}
+		err = json.Unmarshal([]byte(body), &services)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services.Data {
+			if strings.HasPrefix(service.Name, namePrefix) {
+				workloads = append(workloads, model.Workload{Id: service.Id, Name: service.Name, CreatedAt: time.UnixMilli(service.CreatedTS)})
+			}
+		}
+		next = services.Pagination.Next
+	}
+	return workloads, nil
+}
+
+func (r Rancher) getService(id string) (service Service, exists bool, err error) {
+	request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey)
+	resp, body, errs := request.Get(r.url + "services/" + id).End()
+	if len(errs) > 0 {
+		return service, false, errs[0]
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return service, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return service, false, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+	}
+	err = json.Unmarshal([]byte(body), &service)
+	return service, err == nil, err
+}
+
+func (r Rancher) GetContainerLogs(_ string, _ model.LogOptions) (logs io.ReadCloser, err error) {
+	return nil, deploy.ErrNotSupported
+}
+
+func (r Rancher) StopContainer(id string) (err error) {
+	return r.serviceAction(id, "deactivate")
+}
+
+func (r Rancher) StartContainer(id string) (err error) {
+	return r.serviceAction(id, "activate")
+}
+
+func (r Rancher) serviceAction(id string, action string) (err error) {
+	request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey)
+	resp, body, e := request.Post(r.url + "services/" + id + "?action=" + action).End()
+	if len(e) > 0 {
+		return errors.New("could not " + action + " instance: " + e[0].Error())
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		log.Println("ERROR: Rancher response code", resp.StatusCode, "when trying to "+action+" service, Body:", body)
+		return errors.New("unexpected status code while trying to " + action + " container " + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
+
 func (r Rancher) selfCheck() error {
 	request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey)
 	resp, _, _ := request.Get(r.url + "stacks/" + r.stackId).End()
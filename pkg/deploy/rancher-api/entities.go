@@ -32,10 +32,20 @@ type LaunchConfig struct {
 }
 
 type ServiceCollection struct {
-	Data []Service `json:"data"`
+	Data       []Service  `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type Pagination struct {
+	Next string `json:"next"`
 }
 
 type Service struct {
-	Id           string `json:"id"`
-	LaunchConfig `json:"launchConfig,omitempty"`
+	Id                   string `json:"id"`
+	Name                 string `json:"name"`
+	State                string `json:"state"`
+	HealthState          string `json:"healthState"`
+	TransitioningMessage string `json:"transitioningMessage"`
+	CreatedTS            int64  `json:"createdTS"`
+	LaunchConfig         `json:"launchConfig,omitempty"`
 }
@@ -18,11 +18,17 @@ package rancher2_api
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/config"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/parnurzeal/gorequest"
 )
@@ -136,3 +142,195 @@ func (r *Rancher2) ContainerExists(id string) (exists bool, err error) {
 	}
 	return true, nil
 }
+
+func (r *Rancher2) StopContainer(id string) (err error) {
+	return r.scaleWorkload(id, 0)
+}
+
+func (r *Rancher2) StartContainer(id string) (err error) {
+	return r.scaleWorkload(id, 1)
+}
+
+// jobs can not be scaled, only workloads created with restart=true can be stopped and started
+func (r *Rancher2) scaleWorkload(id string, scale int) (err error) {
+	request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey).TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	resp, body, e := request.Put(r.url + "projects/" + r.projectId + "/workloads/deployment:" +
+		r.namespaceId + ":" + id).Send(ScaleRequest{Scale: &scale}).End()
+	if len(e) > 0 {
+		return errors.New("could not scale export: " + e[0].Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("could not scale export: " + body)
+	}
+	return nil
+}
+
+func (r *Rancher2) GetContainerStatus(id string) (status model.DeploymentStatus, err error) {
+	workload, exists, err := r.getWorkload(id)
+	if err != nil {
+		return status, err
+	}
+	if !exists {
+		return model.DeploymentStatus{State: model.DeploymentStateMissing}, nil
+	}
+	status.BackendState = workload.State
+	status.Message = workload.TransitioningMessage
+	switch {
+	case strings.Contains(workload.TransitioningMessage, "CrashLoopBackOff"):
+		status.State = model.DeploymentStateCrashLoop
+	case workload.Scale != nil && *workload.Scale == 0:
+		status.State = model.DeploymentStateStopped
+	case workload.Transitioning == "error":
+		status.State = model.DeploymentStateFailed
+	case workload.State == "active":
+		status.State = model.DeploymentStateRunning
+	case workload.State == "updating" || workload.State == "in-progress":
+		status.State = model.DeploymentStateRestarting
+	case workload.State == "succeeded":
+		status.State = model.DeploymentStateStopped
+	case workload.State == "unavailable" || workload.State == "failed":
+		status.State = model.DeploymentStateFailed
+	default:
+		status.State = model.DeploymentStateUnknown
+	}
+	return status, nil
+}
+
+func (r *Rancher2) GetContainerConfig(id string) (deployed model.DeployedConfig, exists bool, err error) {
+	workload, exists, err := r.getWorkload(id)
+	if err != nil || !exists {
+		return deployed, exists, err
+	}
+	deployed.Env = map[string]string{}
+	for _, container := range workload.Containers {
+		if container.Name != id && len(workload.Containers) > 1 {
+			continue
+		}
+		deployed.Image = container.Image
+		for _, env := range container.Env {
+			deployed.Env[env.Name] = env.Value
+		}
+	}
+	return deployed, true, nil
+}
+
+// ListContainers lists deployments and jobs of the namespace labeled by CreateContainer or matching the name prefix
+func (r *Rancher2) ListContainers(namePrefix string) (workloads []model.Workload, err error) {
+	workloads = []model.Workload{}
+	next := r.url + "projects/" + r.projectId + "/workloads?namespaceId=" + url.QueryEscape(r.namespaceId)
+	for next != "" {
+		request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey).TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+		resp, body, errs := request.Get(next).End()
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+		}
+		collection := WorkloadCollection{}
+		err = json.Unmarshal([]byte(body), &collection)
+		if err != nil {
+			return nil, err
+		}
+		for _, workload := range collection.Data {
+			_, labeled := workload.Labels["kafka2mqtt"]
+			if labeled || strings.HasPrefix(workload.Name, namePrefix) {
+				workloads = append(workloads, model.Workload{Id: workload.Name, Name: workload.Name, CreatedAt: time.UnixMilli(workload.CreatedTS)})
+			}
+		}
+		next = collection.Pagination.Next
+	}
+	return workloads, nil
+}
+
+// getWorkload looks up the deployment with the given id and falls back to a job with the given id
+func (r *Rancher2) getWorkload(id string) (workload Workload, exists bool, err error) {
+	for _, kind := range []string{"deployment", "job"} {
+		request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey).TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+		resp, body, errs := request.Get(r.url + "projects/" + r.projectId + "/workloads/" + kind + ":" +
+			r.namespaceId + ":" + id).End()
+		if len(errs) > 0 {
+			return workload, false, errs[0]
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return workload, false, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+		}
+		err = json.Unmarshal([]byte(body), &workload)
+		return workload, err == nil, err
+	}
+	return workload, false, nil
+}
+
+// GetContainerLogs reads the pod logs through the kubernetes api proxy of rancher.
+// If more than one pod belongs to the workload (e.g. during an update), the logs of the first running pod are returned.
+func (r *Rancher2) GetContainerLogs(id string, options model.LogOptions) (logs io.ReadCloser, err error) {
+	request := gorequest.New().SetBasicAuth(r.accessKey, r.secretKey).TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	resp, body, errs := request.Get(r.url + "projects/" + r.projectId + "/pods").
+		Query("workloadId=deployment:" + r.namespaceId + ":" + id).End()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("unexpected status " + strconv.Itoa(resp.StatusCode))
+	}
+	pods := PodCollection{}
+	err = json.Unmarshal([]byte(body), &pods)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Data) == 0 {
+		return nil, errors.New("no pod found for export")
+	}
+	pod := pods.Data[0]
+	for _, p := range pods.Data {
+		if p.State == "running" {
+			pod = p
+			break
+		}
+	}
+
+	query := url.Values{}
+	query.Set("container", id)
+	if options.Tail > 0 {
+		query.Set("tailLines", strconv.Itoa(options.Tail))
+	}
+	if !options.Since.IsZero() {
+		query.Set("sinceTime", options.Since.UTC().Format(time.RFC3339))
+	}
+	if options.Follow {
+		query.Set("follow", "true")
+	}
+	if options.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	clusterId := strings.Split(r.projectId, ":")[0]
+	logUrl := r.serverUrl() + "/k8s/clusters/" + clusterId + "/api/v1/namespaces/" + r.namespaceId + "/pods/" + pod.Name + "/log?" + query.Encode()
+	req, err := http.NewRequest(http.MethodGet, logUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(r.accessKey, r.secretKey)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	logResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if logResp.StatusCode != http.StatusOK {
+		payload, _ := io.ReadAll(logResp.Body)
+		_ = logResp.Body.Close()
+		return nil, errors.New("unable to read export logs: " + string(payload))
+	}
+	return logResp.Body, nil
+}
+
+// serverUrl returns the rancher base url without the api version path (e.g. https://rancher.example.com for https://rancher.example.com/v3/)
+func (r *Rancher2) serverUrl() string {
+	index := strings.Index(r.url, "/v3")
+	if index < 0 {
+		return strings.TrimSuffix(r.url, "/")
+	}
+	return r.url[:index]
+}
@@ -58,3 +58,39 @@ type Scheduling struct {
 type Node struct {
 	RequireAll []string `json:"requireAll,omitempty"`
 }
+
+type ScaleRequest struct {
+	Scale *int `json:"scale,omitempty"`
+}
+
+type Workload struct {
+	Id                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	Type                 string            `json:"type"`
+	State                string            `json:"state"`
+	Transitioning        string            `json:"transitioning"`
+	TransitioningMessage string            `json:"transitioningMessage"`
+	Scale                *int              `json:"scale"`
+	Containers           []Container       `json:"containers"`
+	Labels               map[string]string `json:"labels"`
+	CreatedTS            int64             `json:"createdTS"`
+}
+
+type WorkloadCollection struct {
+	Data       []Workload `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type Pagination struct {
+	Next string `json:"next"`
+}
+
+type PodCollection struct {
+	Data []Pod `json:"data"`
+}
+
+type Pod struct {
+	Name        string `json:"name"`
+	NamespaceId string `json:"namespaceId"`
+	State       string `json:"state"`
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/deploy/dockerClient"
 	rancher1api "github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/deploy/rancher-api"
 	rancher2api "github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/deploy/rancher2-api"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/kafka"
 	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/verification"
 	permv2 "github.com/SENERGY-Platform/permissions-v2/pkg/client"
 )
@@ -64,7 +65,7 @@ func Start(conf config.Config, ctx context.Context) (wg *sync.WaitGroup, err err
 	permv2Client := permv2.New(conf.PermissionsV2Url)
 	verifier := verification.New(permv2Client)
 
-	ctrl, err := controller.New(conf, data, deploymentClient, verifier, permv2Client)
+	ctrl, err := controller.New(conf, ctx, wg, data, deploymentClient, verifier, permv2Client, kafka.New(conf.KafkaBootstrap))
 	if err != nil {
 		log.Println("ERROR: unable to get controller", err)
 		return wg, err
@@ -78,6 +79,40 @@ func Start(conf config.Config, ctx context.Context) (wg *sync.WaitGroup, err err
 		}
 	}
 
+	err = ctrl.StartReconcileLoop()
+	if err != nil {
+		log.Println("ERROR: unable to start reconciliation", err)
+		return wg, err
+	}
+
+	ctrl.StartScheduler()
+
+	err = ctrl.StartExpiryLoop()
+	if err != nil {
+		log.Println("ERROR: unable to start expiry of instances", err)
+		return wg, err
+	}
+
+	err = ctrl.StartPurgeLoop()
+	if err != nil {
+		log.Println("ERROR: unable to start purge of deleted instances", err)
+		return wg, err
+	}
+
+	err = ctrl.StartOperationWorkers()
+	if err != nil {
+		log.Println("ERROR: unable to start operation workers", err)
+		return wg, err
+	}
+
+	ctrl.StartLifecyclePublisher()
+
+	err = ctrl.StartWebhooks()
+	if err != nil {
+		log.Println("ERROR: unable to start webhooks", err)
+		return wg, err
+	}
+
 	err = api.Start(conf, ctx, ctrl, permv2Client)
 	if err != nil {
 		log.Println("ERROR: unable to start api", err)
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/util"
+	"github.com/segmentio/kafka-go"
+)
+
+// Client resolves and commits the consumer offsets of the topics consumed by the workers and publishes the lifecycle events of instances
+type Client struct {
+	client *kafka.Client
+	writer *kafka.Writer
+}
+
+func New(bootstrap string) *Client {
+	addr := kafka.TCP(strings.Split(bootstrap, ",")...)
+	return &Client{
+		client: &kafka.Client{Addr: addr, Timeout: 10 * time.Second},
+		writer: &kafka.Writer{
+			Addr:         addr,
+			Balancer:     &kafka.Hash{}, // events of an instance stay in order on one partition
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+			WriteTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// PublishLifecycleEvents writes the events keyed by instance id, in the given order per instance
+func (this *Client) PublishLifecycleEvents(topic string, events []model.LifecycleEvent) error {
+	messages := []kafka.Message{}
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{Topic: topic, Key: []byte(event.InstanceId), Value: value, Time: event.Timestamp})
+	}
+	ctx, _ := util.GetTimeoutContext()
+	return this.writer.WriteMessages(ctx, messages...)
+}
+
+// GetOffsetRanges returns the first and the end offset of every partition of the topic
+func (this *Client) GetOffsetRanges(topic string) (first map[int]int64, end map[int]int64, err error) {
+	partitions, err := this.partitions(topic)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstRequests := []kafka.OffsetRequest{}
+	endRequests := []kafka.OffsetRequest{}
+	for _, partition := range partitions {
+		firstRequests = append(firstRequests, kafka.FirstOffsetOf(partition))
+		endRequests = append(endRequests, kafka.LastOffsetOf(partition))
+	}
+	// a partition may only be requested once per call
+	firstOffsets, err := this.listOffsets(topic, firstRequests)
+	if err != nil {
+		return nil, nil, err
+	}
+	endOffsets, err := this.listOffsets(topic, endRequests)
+	if err != nil {
+		return nil, nil, err
+	}
+	first = map[int]int64{}
+	end = map[int]int64{}
+	for _, offsets := range firstOffsets {
+		first[offsets.Partition] = offsets.FirstOffset
+	}
+	for _, offsets := range endOffsets {
+		end[offsets.Partition] = offsets.LastOffset
+	}
+	return first, end, nil
+}
+
+// GetOffsetsForTime returns the offset of the first message at or after the timestamp for every partition of the topic.
+// Partitions without such a message get their end offset.
+func (this *Client) GetOffsetsForTime(topic string, at time.Time) (offsets map[int]int64, err error) {
+	partitions, err := this.partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	requests := []kafka.OffsetRequest{}
+	for _, partition := range partitions {
+		requests = append(requests, kafka.TimeOffsetOf(partition, at))
+	}
+	timeOffsets, err := this.listOffsets(topic, requests)
+	if err != nil {
+		return nil, err
+	}
+	_, end, err := this.GetOffsetRanges(topic)
+	if err != nil {
+		return nil, err
+	}
+	offsets = map[int]int64{}
+	for _, partitionOffsets := range timeOffsets {
+		offsets[partitionOffsets.Partition] = end[partitionOffsets.Partition]
+		for offset := range partitionOffsets.Offsets {
+			if offset >= 0 {
+				offsets[partitionOffsets.Partition] = offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// GetCommittedOffsets returns the offsets committed by the consumer group, partitions without commit are missing
+func (this *Client) GetCommittedOffsets(groupId string, topic string) (offsets map[int]int64, err error) {
+	partitions, err := this.partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	ctx, _ := util.GetTimeoutContext()
+	resp, err := this.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupId, Topics: map[string][]int{topic: partitions}})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	offsets = map[int]int64{}
+	for _, partition := range resp.Topics[topic] {
+		if partition.Error != nil {
+			return nil, partition.Error
+		}
+		if partition.CommittedOffset >= 0 {
+			offsets[partition.Partition] = partition.CommittedOffset
+		}
+	}
+	return offsets, nil
+}
+
+// CommitOffsets sets the offsets of the consumer group, which must not have active members
+func (this *Client) CommitOffsets(groupId string, topic string, offsets map[int]int64) error {
+	commits := []kafka.OffsetCommit{}
+	for partition, offset := range offsets {
+		commits = append(commits, kafka.OffsetCommit{Partition: partition, Offset: offset})
+	}
+	ctx, _ := util.GetTimeoutContext()
+	resp, err := this.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      groupId,
+		GenerationID: -1, // commit outside of a group generation
+		Topics:       map[string][]kafka.OffsetCommit{topic: commits},
+	})
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	for _, partition := range resp.Topics[topic] {
+		if partition.Error != nil {
+			errs = append(errs, fmt.Errorf("partition %v: %w", partition.Partition, partition.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (this *Client) partitions(topic string) (partitions []int, err error) {
+	ctx, _ := util.GetTimeoutContext()
+	resp, err := this.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range resp.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, t.Error
+		}
+		for _, partition := range t.Partitions {
+			partitions = append(partitions, partition.ID)
+		}
+		return partitions, nil
+	}
+	return nil, errors.New("unknown topic " + topic)
+}
+
+func (this *Client) listOffsets(topic string, requests []kafka.OffsetRequest) (result []kafka.PartitionOffsets, err error) {
+	ctx, _ := util.GetTimeoutContext()
+	resp, err := this.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range resp.Topics[topic] {
+		if partition.Error != nil {
+			return nil, fmt.Errorf("partition %v: %w", partition.Partition, partition.Error)
+		}
+	}
+	return resp.Topics[topic], nil
+}
@@ -21,34 +21,514 @@ import "time"
 type Instances []Instance
 
 type Instance struct {
-	FilterType          string    `json:"FilterType,omitempty" validate:"required"`
-	Filter              string    `json:"Filter,omitempty" validate:"required"`
-	Name                string    `json:"Name,omitempty" validate:"required"`
-	EntityName          string    `json:"EntityName,omitempty" validate:"required"`
-	ServiceName         string    `json:"ServiceName,omitempty" validate:"required"`
+	FilterType          string            `json:"FilterType,omitempty" validate:"required"`
+	Filter              string            `json:"Filter,omitempty" validate:"required"`
+	Name                string            `json:"Name,omitempty" validate:"required"`
+	EntityName          string            `json:"EntityName,omitempty" validate:"required"`
+	ServiceName         string            `json:"ServiceName,omitempty" validate:"required"`
+	Description         string            `json:"Description,omitempty"`
+	Topic               string            `json:"Topic,omitempty" validate:"required"`
+	Generated           bool              `json:"generated,omitempty"`
+	Offset              string            `json:"Offset,omitempty" validate:"required"`
+	Values              []Value           `json:"Values,omitempty"`
+	UserId              string            `json:"-"`
+	ServiceId           string            `json:"-"`
+	CustomMqttBroker    *string           `json:"CustomMqttBroker,omitempty"`
+	CustomMqttUser      *string           `json:"CustomMqttUser,omitempty"`
+	CustomMqttPassword  *string           `json:"CustomMqttPassword,omitempty"`
+	CustomMqttBaseTopic *string           `json:"CustomMqttBaseTopic,omitempty"`
+	ImageVersion        *string           `json:"ImageVersion,omitempty"`
+	TemplateId          string            `json:"TemplateId,omitempty"`
+	Revision            int64             `json:"Revision"`
+	Schedule            *Schedule         `json:"Schedule,omitempty"`
+	ScheduleState       string            `json:"ScheduleState,omitempty"` // one of the ScheduleState constants, maintained by the scheduler
+	ExpiresAt           *time.Time        `json:"ExpiresAt,omitempty"`
+	ExpiryAction        string            `json:"ExpiryAction,omitempty"`   // one of the ExpiryAction constants, defaults to ExpiryActionDelete
+	ExpiryWarnedAt      *time.Time        `json:"ExpiryWarnedAt,omitempty"` // set when the expiry warning has been sent
+	Paused              bool              `json:"Paused"`
+	DeletedAt           *time.Time        `json:"DeletedAt,omitempty"`    // set while the instance is in the trash
+	KafkaGroupId        string            `json:"KafkaGroupId,omitempty"` // active consumer group, empty for the default group named like the id
+	Id                  string            `json:"ID"`
+	CreatedAt           time.Time         `json:"CreatedAt"`
+	UpdatedAt           time.Time         `json:"UpdatedAt"`
+	Status              *DeploymentStatus `json:"Status,omitempty" bson:"-"`
+}
+
+const (
+	ExpiryActionDelete = "delete" // expired instances are moved to the trash
+	ExpiryActionPause  = "pause"  // expired instances are paused until the expiry is extended or removed
+)
+
+const (
+	ScheduleStateActive   = "active"
+	ScheduleStateInactive = "inactive"
+)
+
+// Schedule restricts the time the workload of an instance runs. The instance is active between Start and End
+// and, if Windows are given, only within one of them.
+type Schedule struct {
+	TimeZone string           `json:"TimeZone,omitempty"` // IANA time zone of the windows, defaults to UTC
+	Start    *time.Time       `json:"Start,omitempty"`
+	End      *time.Time       `json:"End,omitempty"`
+	Windows  []ScheduleWindow `json:"Windows,omitempty"`
+}
+
+// ScheduleWindow is a daily active window. From and To are times of day formatted as 15:04, a window with To before From ends on the next day.
+// Days limits the window to the days it starts on (mon, tue, wed, thu, fri, sat, sun), empty means every day.
+type ScheduleWindow struct {
+	Days []string `json:"Days,omitempty"`
+	From string   `json:"From"`
+	To   string   `json:"To"`
+}
+
+type InstancesResponse struct {
+	Total     int64     `json:"total,omitempty"`
+	Count     int       `json:"count,omitempty"`
+	Instances Instances `json:"instances,omitempty"`
+}
+
+type Value struct {
+	Name string `json:"Name"`
+	Path string `json:"Path"`
+}
+
+const (
+	DeploymentStateRunning    = "running"
+	DeploymentStateRestarting = "restarting"
+	DeploymentStateCrashLoop  = "crashloop"
+	DeploymentStateStopped    = "stopped"
+	DeploymentStateFailed     = "failed"
+	DeploymentStateMissing    = "missing"
+	DeploymentStateUnknown    = "unknown"
+)
+
+// DeploymentStatus is the live state of the workload of an instance as reported by the deployment backend.
+// State is one of the DeploymentState constants, BackendState contains the unmodified state of the backend.
+type DeploymentStatus struct {
+	State        string `json:"State"`
+	BackendState string `json:"BackendState,omitempty"`
+	Restarts     int    `json:"Restarts,omitempty"`
+	Message      string `json:"Message,omitempty"`
+}
+
+// LogOptions selects the log lines of an instance workload.
+// Tail <= 0 returns all available lines, a zero Since does not limit the log by time.
+type LogOptions struct {
+	Tail       int
+	Since      time.Time
+	Follow     bool
+	Timestamps bool
+}
+
+// ReconcileReport summarizes one comparison of all stored instances with the deployment backend
+type ReconcileReport struct {
+	Checked   int               `json:"Checked"`
+	Recreated []string          `json:"Recreated"`
+	Failed    map[string]string `json:"Failed"`
+}
+
+// DeployedConfig is the configuration of a workload as currently known to the deployment backend.
+// Env may contain additional variables set by the backend or the image.
+type DeployedConfig struct {
+	Image string
+	Env   map[string]string
+}
+
+type DriftReport struct {
+	Checked int             `json:"Checked"`
+	Drifted []InstanceDrift `json:"Drifted"`
+}
+
+// InstanceDrift lists the differences between the desired and the deployed configuration of an instance.
+// Values of secret variables are masked.
+type InstanceDrift struct {
+	InstanceId    string     `json:"InstanceId"`
+	Missing       bool       `json:"Missing,omitempty"`
+	DesiredImage  string     `json:"DesiredImage,omitempty"`
+	DeployedImage string     `json:"DeployedImage,omitempty"`
+	Env           []EnvDrift `json:"Env,omitempty"`
+	Redeployed    bool       `json:"Redeployed,omitempty"`
+	Error         string     `json:"Error,omitempty"`
+}
+
+type EnvDrift struct {
+	Name     string `json:"Name"`
+	Desired  string `json:"Desired"`
+	Deployed string `json:"Deployed"`
+}
+
+// Workload is a container, service or deployment of the deployment backend. Id matches Instance.ServiceId.
+type Workload struct {
+	Id        string    `json:"Id"`
+	Name      string    `json:"Name"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type OrphanReport struct {
+	Orphans []Workload        `json:"Orphans"`
+	Removed []string          `json:"Removed"`
+	Failed  map[string]string `json:"Failed"`
+}
+
+type UpgradeOptions struct {
+	Concurrency int    `json:"Concurrency"` // instances redeployed in parallel per batch, defaults to 1
+	BatchPause  string `json:"BatchPause"`  // duration to wait between batches, e.g. "30s"
+	MaxFailures int    `json:"MaxFailures"` // the upgrade stops after this many failures, defaults to 1
+}
+
+type UpgradeProgress struct {
+	Running    bool              `json:"Running"`
+	Image      string            `json:"Image"`
+	Options    UpgradeOptions    `json:"Options"`
+	StartedAt  time.Time         `json:"StartedAt"`
+	FinishedAt *time.Time        `json:"FinishedAt,omitempty"`
+	Total      int               `json:"Total"`
+	Upgraded   int               `json:"Upgraded"`
+	Failed     map[string]string `json:"Failed"`
+	Error      string            `json:"Error,omitempty"`
+
+	CancelRequested bool      `json:"CancelRequested,omitempty"` // set by CancelUpgrade, polled by the replica running the upgrade
+	UpdatedAt       time.Time `json:"UpdatedAt"`                 // refreshed by the replica running the upgrade while it is alive
+}
+
+// ValidationResult is the outcome of a dry run. Env contains the rendered worker configuration with masked secrets.
+type ValidationResult struct {
+	Valid  bool              `json:"Valid"`
+	Errors []string          `json:"Errors,omitempty"`
+	Image  string            `json:"Image,omitempty"`
+	Env    map[string]string `json:"Env,omitempty"`
+}
+
+const (
+	CompensationRemoveInstance  = "remove_instance"  // rollback of a failed create: removes record, workload and permissions
+	CompensationCleanupDeleted  = "cleanup_deleted"  // completes a delete: removes workload and permissions of a removed record
+	CompensationRestoreInstance = "restore_instance" // rollback of a failed update: redeploys and stores the previous version
+	CompensationRemoveWorkload  = "remove_workload"  // completes a soft delete: removes the workload of a trashed record
+)
+
+// Compensation is a rollback step that could not be completed immediately and is retried by the reconciliation
+type Compensation struct {
+	Id           string    `json:"Id"`
+	Action       string    `json:"Action"`
+	InstanceId   string    `json:"InstanceId"`
+	ServiceId    string    `json:"ServiceId,omitempty"`
+	Image        string    `json:"Image,omitempty"`        // restore_instance: previously deployed image
+	KafkaGroupId string    `json:"KafkaGroupId,omitempty"` // restore_instance: previously deployed consumer group
+	Instance     *Instance `json:"Instance,omitempty"`     // restore_instance: previous version of the instance
+	Attempts     int       `json:"Attempts"`
+	LastError    string    `json:"LastError,omitempty"`
+	CreatedAt    time.Time `json:"CreatedAt"`
+	UpdatedAt    time.Time `json:"UpdatedAt"`
+}
+
+type CompensationReport struct {
+	Retried  int               `json:"Retried"`
+	Resolved []string          `json:"Resolved"`
+	Failed   map[string]string `json:"Failed"`
+}
+
+// InstanceDefinition is the portable part of an instance. Ids, runtime state and secrets are never exported,
+// a CustomMqttPassword may be provided on import.
+type InstanceDefinition struct {
+	Name                string     `json:"Name"`
+	Description         string     `json:"Description,omitempty"`
+	EntityName          string     `json:"EntityName,omitempty"`
+	ServiceName         string     `json:"ServiceName,omitempty"`
+	FilterType          string     `json:"FilterType"`
+	Filter              string     `json:"Filter"`
+	Topic               string     `json:"Topic"`
+	Offset              string     `json:"Offset"`
+	Values              []Value    `json:"Values,omitempty"`
+	CustomMqttBroker    *string    `json:"CustomMqttBroker,omitempty"`
+	CustomMqttUser      *string    `json:"CustomMqttUser,omitempty"`
+	CustomMqttPassword  *string    `json:"CustomMqttPassword,omitempty"`
+	CustomMqttBaseTopic *string    `json:"CustomMqttBaseTopic,omitempty"`
+	ImageVersion        *string    `json:"ImageVersion,omitempty"`
+	Schedule            *Schedule  `json:"Schedule,omitempty"`
+	ExpiresAt           *time.Time `json:"ExpiresAt,omitempty"`
+	ExpiryAction        string     `json:"ExpiryAction,omitempty"`
+}
+
+type InstanceDefinitions struct {
+	Instances []InstanceDefinition `json:"Instances"`
+}
+
+const (
+	ImportActionCreated    = "created"
+	ImportActionUpdated    = "updated"
+	ImportActionUnchanged  = "unchanged"
+	ImportActionFailed     = "failed"
+	ImportActionSkipped    = "skipped"     // atomic import aborted before the item was applied
+	ImportActionRolledBack = "rolled_back" // atomic import failed after the item was applied
+)
+
+type ImportItemResult struct {
+	Name       string `json:"Name"`
+	Action     string `json:"Action"`
+	InstanceId string `json:"InstanceId,omitempty"`
+	Error      string `json:"Error,omitempty"`
+}
+
+// ImportReport contains one result per imported definition in document order. Applied is false if an atomic import has been aborted.
+type ImportReport struct {
+	Applied bool               `json:"Applied"`
+	Items   []ImportItemResult `json:"Items"`
+}
+
+// Template contains the settings shared by all instances created from it
+type Template struct {
+	Id                  string    `json:"ID"`
+	Name                string    `json:"Name" validate:"required"`
 	Description         string    `json:"Description,omitempty"`
-	Topic               string    `json:"Topic,omitempty" validate:"required"`
-	Generated           bool      `json:"generated,omitempty"`
-	Offset              string    `json:"Offset,omitempty" validate:"required"`
-	Values              []Value   `json:"Values,omitempty"`
 	UserId              string    `json:"-"`
-	ServiceId           string    `json:"-"`
+	Offset              string    `json:"Offset" validate:"required"`
+	Values              []Value   `json:"Values,omitempty"`
 	CustomMqttBroker    *string   `json:"CustomMqttBroker,omitempty"`
 	CustomMqttUser      *string   `json:"CustomMqttUser,omitempty"`
 	CustomMqttPassword  *string   `json:"CustomMqttPassword,omitempty"`
 	CustomMqttBaseTopic *string   `json:"CustomMqttBaseTopic,omitempty"`
-	Id                  string    `json:"ID"`
+	ImageVersion        *string   `json:"ImageVersion,omitempty"`
 	CreatedAt           time.Time `json:"CreatedAt"`
 	UpdatedAt           time.Time `json:"UpdatedAt"`
 }
 
-type InstancesResponse struct {
-	Total     int64     `json:"total,omitempty"`
-	Count     int       `json:"count,omitempty"`
-	Instances Instances `json:"instances,omitempty"`
+// TemplatePropagationReport lists the linked instances updated after a template change
+type TemplatePropagationReport struct {
+	Updated []string          `json:"Updated"`
+	Failed  map[string]string `json:"Failed"`
 }
 
-type Value struct {
-	Name string `json:"Name"`
-	Path string `json:"Path"`
+// InstanceRevision is a stored version of an instance, written by UserId on every change of its configuration
+type InstanceRevision struct {
+	InstanceId string    `json:"InstanceId"`
+	Revision   int64     `json:"Revision"`
+	UserId     string    `json:"UserId"`
+	Timestamp  time.Time `json:"Timestamp"`
+	Instance   Instance  `json:"Instance"`
+}
+
+type FieldChange struct {
+	Field string      `json:"Field"`
+	From  interface{} `json:"From"`
+	To    interface{} `json:"To"`
+}
+
+type InstanceDiff struct {
+	From    int64         `json:"From"`
+	To      int64         `json:"To"`
+	Changes []FieldChange `json:"Changes"`
+}
+
+const (
+	AuditActionCreate         = "instance.create"
+	AuditActionClone          = "instance.clone"
+	AuditActionUpdate         = "instance.update"
+	AuditActionRollback       = "instance.rollback"
+	AuditActionDelete         = "instance.delete"
+	AuditActionRestore        = "instance.restore"
+	AuditActionPurge          = "instance.purge"
+	AuditActionExpire         = "instance.expire"
+	AuditActionPause          = "instance.pause"
+	AuditActionResume         = "instance.resume"
+	AuditActionReplay         = "instance.replay"
+	AuditActionShare          = "instance.permissions"
+	AuditActionTemplateCreate = "template.create"
+	AuditActionTemplateUpdate = "template.update"
+	AuditActionTemplateDelete = "template.delete"
+	AuditActionTemplateShare  = "template.permissions"
+	AuditActionDriftRedeploy  = "admin.drift_redeploy"
+	AuditActionUpgrade        = "admin.upgrade"
+	AuditActionQuota          = "admin.quota"
+	AuditOutcomeSuccess       = "success"
+	AuditOutcomeFailure       = "failure"
+)
+
+const (
+	LifecycleEventCreated  = "created"
+	LifecycleEventUpdated  = "updated"
+	LifecycleEventPaused   = "paused"
+	LifecycleEventResumed  = "resumed"
+	LifecycleEventDeleted  = "deleted"
+	LifecycleEventRestored = "restored"
+	LifecycleEventPurged   = "purged"
+	LifecycleEventFailed   = "failed"
+)
+
+// LifecycleEvent is published to the lifecycle_topic, keyed by InstanceId, when an instance changed or a change of it failed.
+// Events are delivered at least once, consumers may deduplicate them by Id.
+type LifecycleEvent struct {
+	Id         string        `json:"Id"`
+	Type       string        `json:"Type"`             // one of the LifecycleEvent constants
+	Action     string        `json:"Action,omitempty"` // audit action of failed events, the other events are derived from the stored change
+	InstanceId string        `json:"InstanceId"`
+	UserId     string        `json:"UserId"`
+	Changes    []FieldChange `json:"Changes,omitempty"` // changed configuration fields with masked secrets
+	Error      string        `json:"Error,omitempty"`
+	Timestamp  time.Time     `json:"Timestamp"`
+}
+
+// AuditEvent records a mutating call. Changes contains the changed configuration fields with masked secrets.
+type AuditEvent struct {
+	Id         string        `json:"Id"`
+	Timestamp  time.Time     `json:"Timestamp"`
+	UserId     string        `json:"UserId"`
+	Action     string        `json:"Action"`
+	InstanceId string        `json:"InstanceId,omitempty"`
+	TemplateId string        `json:"TemplateId,omitempty"`
+	Changes    []FieldChange `json:"Changes,omitempty"`
+	Outcome    string        `json:"Outcome"`
+	Error      string        `json:"Error,omitempty"`
+}
+
+// AuditQuery filters audit events, empty fields are ignored
+type AuditQuery struct {
+	From       *time.Time
+	To         *time.Time
+	UserId     string
+	InstanceId string
+	Action     string
+	Limit      int64
+	Offset     int64
+}
+
+const (
+	QuotaTypeUser      = "user"
+	QuotaTypeRole      = "role"
+	QuotaSourceDefault = "default"
+)
+
+// Quota overrides the default_instance_quota for a single user or for all users with a permissions role.
+// A Limit of 0 means unlimited.
+type Quota struct {
+	Type    string `json:"Type"`
+	Subject string `json:"Subject"` // user id or role name
+	Limit   int64  `json:"Limit"`
+}
+
+// QuotaUsage compares the instances of a user with the effective limit. A Limit of 0 means unlimited.
+type QuotaUsage struct {
+	UserId string `json:"UserId"`
+	Used   int64  `json:"Used"`
+	Limit  int64  `json:"Limit"`
+	Source string `json:"Source"`         // default, user or role
+	Role   string `json:"Role,omitempty"` // role of the applied override
+}
+
+// Lock is a lease shared by all manager replicas, it is released by its owner or expires at LockedUntil
+type Lock struct {
+	Key         string    `json:"Key"`
+	Owner       string    `json:"Owner"`
+	LockedUntil time.Time `json:"LockedUntil"`
+}
+
+// ReplayRequest restarts the consumption of an instance from a point in time or from explicit offsets, exactly one of them has to be set.
+// Partitions missing in Offsets continue at their current position.
+type ReplayRequest struct {
+	From    *time.Time        `json:"From,omitempty"`
+	Offsets []PartitionOffset `json:"Offsets,omitempty"`
+}
+
+type PartitionOffset struct {
+	Partition int   `json:"Partition"`
+	Offset    int64 `json:"Offset"`
+}
+
+// ReplayResult contains the consumer group the instance was redeployed with and the offsets it starts at
+type ReplayResult struct {
+	KafkaGroupId string            `json:"KafkaGroupId"`
+	Offsets      []PartitionOffset `json:"Offsets"`
+}
+
+const (
+	OperationTypeCreate = "instance.create"
+	OperationTypeUpdate = "instance.update"
+	OperationTypePatch  = "instance.patch"
+
+	OperationStatusPending   = "pending"
+	OperationStatusRunning   = "running"
+	OperationStatusSucceeded = "succeeded"
+	OperationStatusFailed    = "failed"
+)
+
+// Operation is an asynchronously executed create or update of an instance. Operations are queued in the database and
+// processed by any manager replica, operations interrupted by a restart are picked up again once their lease expires.
+type Operation struct {
+	Id          string     `json:"Id"`
+	Type        string     `json:"Type"`   // one of the OperationType constants
+	Status      string     `json:"Status"` // one of the OperationStatus constants
+	UserId      string     `json:"UserId"`
+	InstanceId  string     `json:"InstanceId"`
+	Result      *Instance  `json:"Result,omitempty"` // created or updated instance with masked secrets
+	Error       string     `json:"Error,omitempty"`
+	Code        int        `json:"Code,omitempty"` // http status code the synchronous call would have returned
+	Attempts    int        `json:"Attempts"`
+	CreatedAt   time.Time  `json:"CreatedAt"`
+	StartedAt   *time.Time `json:"StartedAt,omitempty"`
+	FinishedAt  *time.Time `json:"FinishedAt,omitempty"`
+	Instance    *Instance  `json:"-"` // request of create and update including the CustomMqttPassword, removed when finished or failed
+	Patch       string     `json:"-"` // request of patch, may contain the CustomMqttPassword, removed when finished or failed
+	IfMatch     []int64    `json:"-"`
+	Roles       []string   `json:"-"` // roles of the caller for the quota check, the operation runs without its token
+	LockedBy    string     `json:"-"` // replica processing the operation
+	LockedUntil *time.Time `json:"-"`
+}
+
+// Webhook receives signed notifications about deployment state changes of a single instance of its user,
+// or of all instances of its user if InstanceId is empty. States limits the notifications to changes into these
+// DeploymentState constants, all changes are notified if it is empty.
+type Webhook struct {
+	Id         string    `json:"Id"`
+	UserId     string    `json:"UserId"`
+	InstanceId string    `json:"InstanceId,omitempty"`
+	Url        string    `json:"Url"`
+	States     []string  `json:"States,omitempty"`
+	Secret     string    `json:"Secret,omitempty"` // HMAC-SHA256 key of the X-Signature header, only returned on creation
+	CreatedAt  time.Time `json:"CreatedAt"`
+	Roles      []string  `json:"-"` // roles of the owner on creation, notifications require read access with them
+	Groups     []string  `json:"-"` // groups of the owner on creation, notifications require read access with them
+}
+
+const (
+	WebhookEventStateChanged = "instance.state_changed"
+	WebhookEventTest         = "test"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookNotification is the body posted to webhooks
+type WebhookNotification struct {
+	Id           string    `json:"Id"`    // delivery id, repeated on retries
+	Event        string    `json:"Event"` // one of the WebhookEvent constants
+	InstanceId   string    `json:"InstanceId,omitempty"`
+	InstanceName string    `json:"InstanceName,omitempty"`
+	UserId       string    `json:"UserId"`
+	From         string    `json:"From,omitempty"` // previous DeploymentState
+	To           string    `json:"To,omitempty"`   // current DeploymentState
+	Message      string    `json:"Message,omitempty"`
+	Timestamp    time.Time `json:"Timestamp"`
+}
+
+// WebhookDelivery logs the delivery of a notification to a webhook. Failed attempts are retried with backoff
+// until NextAttemptAt, deliveries failing too often end as failed.
+type WebhookDelivery struct {
+	Id            string              `json:"Id"`
+	WebhookId     string              `json:"WebhookId"`
+	UserId        string              `json:"UserId"`
+	Notification  WebhookNotification `json:"Notification"`
+	Status        string              `json:"Status"` // one of the WebhookDelivery constants
+	Attempts      int                 `json:"Attempts"`
+	ResponseCode  int                 `json:"ResponseCode,omitempty"` // status code of the last attempt
+	LastError     string              `json:"LastError,omitempty"`    // connection error or response status of the last attempt, response bodies are not recorded
+	CreatedAt     time.Time           `json:"CreatedAt"`
+	NextAttemptAt *time.Time          `json:"NextAttemptAt,omitempty"`
+	FinishedAt    *time.Time          `json:"FinishedAt,omitempty"`
+}
+
+// InstanceState is the last deployment state of an instance observed for webhook notifications
+type InstanceState struct {
+	InstanceId string    `json:"InstanceId"`
+	State      string    `json:"State"`
+	ChangedAt  time.Time `json:"ChangedAt"`
 }
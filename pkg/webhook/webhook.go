@@ -0,0 +1,144 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/SENERGY-Platform/kafka2mqtt-manager/pkg/model"
+)
+
+const SignatureHeader = "X-Signature"
+const EventHeader = "X-Event"
+const DeliveryHeader = "X-Delivery"
+const TimestampHeader = "X-Timestamp"
+
+// ErrInternalAddress is returned for webhooks resolving to loopback, private, link-local or other internal addresses,
+// e.g. the cloud metadata service or services of the cluster the manager runs in
+var ErrInternalAddress = errors.New("webhook address is not publicly routable")
+
+// ranges not covered by the netip.Addr checks of IsPublicAddress
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade nat, used by some cluster networks
+	netip.MustParsePrefix("192.0.0.0/24"),  // protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, including broadcast
+	netip.MustParsePrefix("::/96"),         // deprecated ipv4-compatible ipv6 addresses
+}
+
+// ipv6 prefixes embedding an ipv4 address, which has to be public as well
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+var sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse // the signature belongs to the registered url
+	},
+	Transport: &http.Transport{
+		Proxy:               nil, // a proxy would connect to internal addresses on behalf of the manager
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: controlPublicAddress}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// Send posts the notification to url. The X-Signature header contains "sha256=" and the hex encoded HMAC-SHA256
+// of "<X-Timestamp>.<body>" keyed with the secret. code is the response status, 0 if no response was received.
+// Internal addresses are rejected with ErrInternalAddress, the response body is never read.
+func Send(url string, secret string, notification model.WebhookNotification) (code int, err error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, notification.Event)
+	req.Header.Set(DeliveryHeader, notification.Id)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, errors.New("unexpected webhook response status " + strconv.Itoa(resp.StatusCode))
+	}
+	return resp.StatusCode, nil
+}
+
+// IsPublicAddress reports if webhooks may connect to ip
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false // covers loopback, link-local including the metadata service, multicast and unspecified addresses
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	raw := ip.As16()
+	if nat64Prefix.Contains(ip) {
+		return IsPublicAddress(netip.AddrFrom4([4]byte(raw[12:16])))
+	}
+	if sixToFourPrefix.Contains(ip) {
+		return IsPublicAddress(netip.AddrFrom4([4]byte(raw[2:6])))
+	}
+	return true
+}
+
+// controlPublicAddress rejects connections to internal addresses. It runs for every resolved address right before
+// connecting, so hosts resolving to an internal address only after their registration (DNS rebinding) are rejected as well.
+func controlPublicAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddress(ip) {
+		return ErrInternalAddress
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}